@@ -3,16 +3,27 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/joho/godotenv"
+	"github.com/krud3/prueba-tecnica/internal/adapters/cache"
+	"github.com/krud3/prueba-tecnica/internal/adapters/memory"
 	"github.com/krud3/prueba-tecnica/internal/adapters/rest"
 	"github.com/krud3/prueba-tecnica/internal/adapters/storage"
+	"github.com/krud3/prueba-tecnica/internal/adapters/stream"
+	"github.com/krud3/prueba-tecnica/internal/config"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
 	"github.com/krud3/prueba-tecnica/internal/core/services"
+	"github.com/krud3/prueba-tecnica/migrations"
+	"gorm.io/gorm"
 )
 
 // @title API de Órdenes de Servicio
@@ -23,62 +34,184 @@ import (
 // @BasePath /api/v1
 func main() {
 
-	// get env
-	if err := godotenv.Load(); err != nil {
-		log.Println("Por favor suministrar el .env en el root de la manera en que .env.example lo dice.")
+	// config file, .env and env, stops here if something is missing
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Error cargando la configuración: %v", err)
+	}
+
+	// `api migrate <command>` only touches the database, see migrate.go
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := cfg.ValidateMigrate(); err != nil {
+			log.Fatalf("Configuración inválida, revisar el .env como lo dice .env.example:\n%v", err)
+		}
+		if err := runMigrate(cfg, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// scheduling rules of work orders, its errors are shown with the rest of the config
+	policy, policyErr := newPolicy(cfg.Rules)
+	if err := errors.Join(cfg.ValidateAPI(), policyErr); err != nil {
+		log.Fatalf("Configuración inválida, revisar el .env como lo dice .env.example:\n%v", err)
+	}
+
+	// create repository according to STORAGE_DRIVER, postgres by default
+	var customerRepo ports.CustomerRepository
+	var workOrderRepo ports.WorkOrderRepository
+	var uow ports.UnitOfWork
+	// only set with postgres or sqlite, other stores can use it
+	var gormDB *gorm.DB
+
+	switch cfg.Storage.Driver {
+	case "postgres", "sqlite":
+		db, err := storage.NewGormDB(cfg.Storage.Driver, cfg.Database)
+		if err != nil {
+			// like printf but ends with exit(0)
+			log.Fatalf("Error conectando la base de datos: %v", err)
+		}
+		log.Println("Conexión establecida con la base de datos.")
+
+		if cfg.Database.MigrateOnStart {
+			migrator, err := storage.NewGormMigrator(db, migrations.For(cfg.Storage.Driver))
+			if err != nil {
+				log.Fatalf("Error leyendo las migraciones: %v", err)
+			}
+			migrateOnStart(migrator)
+		}
+
+		customerRepo = storage.NewGormCustomerRepository(db)
+		workOrderRepo = storage.NewGormWorkOrderRepository(db)
+		uow = storage.NewGormUnitOfWork(db)
+		gormDB = db
+	case "memory":
+		// no database needed, data is lost when the server stops
+		db := memory.NewDB()
+		log.Println("Usando almacenamiento en memoria.")
+
+		customerRepo = memory.NewMemoryCustomerRepository(db)
+		workOrderRepo = memory.NewMemoryWorkOrderRepository(db)
+		uow = memory.NewMemoryUnitOfWork(db)
 	}
 
-	db, err := storage.NewGormDB()
+	// stream for redis, cmd/worker reads it. EVENT_STREAMS sends some events to other streams
+	streamRoutes, err := stream.ParseRoutes(cfg.Events.Streams)
 	if err != nil {
-		// like printf but ends with exit(0)
-		log.Fatalf("Error conectando la base de datos: %v", err)
+		log.Fatalf("EVENT_STREAMS inválido: %v", err)
 	}
-	log.Println("Conexión establecida con la base de datos.")
 
-	redisAddr := os.Getenv("REDIS_ADDR")
-	if redisAddr == "" {
-		log.Println("Por favor suministrar el .env en el root de la manera en que .env.example lo dice.")
+	// create event publisher according to EVENT_PUBLISHER, redis if empty
+	var publisher ports.EventPublisher
+
+	// shared by everything that uses redis, nil until something needs it
+	var redisClient *redis.Client
+
+	if cfg.UsesRedis() {
+		redisClient, err = cache.NewRedisClient(cfg.Redis)
+		if err != nil {
+			log.Fatalf("Error contectando a Redis: %v", err)
+		}
+		log.Println("Conectado a Redis.")
+	}
+
+	switch cfg.Events.Publisher {
+	case "redis":
+		publisher = stream.NewRedisStreamPublisher(redisClient, cfg.Events.Stream, streamRoutes)
+	case "memory":
+		// events are only kept in memory, nothing is sent
+		publisher = memory.NewRecordingPublisher()
+		log.Println("Usando publicador de eventos en memoria.")
 	}
 
-	redisClient := redis.NewClient(&redis.Options{
-		Addr: redisAddr,
-	})
-	// test conection with redis
-	if _, err := redisClient.Ping(context.Background()).Result(); err != nil {
-		log.Fatalf("Error contectando a Redis: %v", err)
+	// create store of Idempotency-Key responses according to IDEMPOTENCY_STORE, follows
+	// STORAGE_DRIVER if empty
+	var idempotencyStore ports.IdempotencyStore
+
+	switch driver := cfg.Idempotency.Store; {
+	case driver == "postgres" || (driver == "" && gormDB != nil):
+		idempotencyStore = storage.NewGormIdempotencyStore(gormDB)
+	case driver == "redis":
+		idempotencyStore = cache.NewRedisIdempotencyStore(redisClient)
+	default:
+		// keys are lost when the server stops
+		idempotencyStore = memory.NewMemoryIdempotencyStore()
 	}
-	log.Println("Conectado a Redis.")
 
-	// create repository
-	customerRepo := storage.NewGormCustomerRepository(db)
-	workOrderRepo := storage.NewGormWorkOrderRepository(db)
+	// stops on ctrl+c or when docker stops the container
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// send outbox events to the stream in background, retries every second while it fails
+	// it has its own context, it keeps sending the events of the requests that finish while the
+	// server shuts down
+	relay := services.NewOutboxRelay(uow, publisher, time.Second, 100)
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	var relayWG sync.WaitGroup
+	relayWG.Add(1)
+	go func() {
+		defer relayWG.Done()
+		relay.Run(relayCtx)
+	}()
 
-	// stream for redis
-	streamName := "work_orders_stream"
 	// create services passing repositories
-	customerService := services.NewCustomerService(customerRepo)
-	workOrderService := services.NewWorkOrderService(workOrderRepo, customerRepo, redisClient, streamName)
+	customerService := services.NewCustomerService(customerRepo, uow)
+	workOrderService := services.NewWorkOrderService(workOrderRepo, customerRepo, uow, policy)
 
 	// create API handlers passing services
 	customerHandler := rest.NewCustomerHandler(customerService)
 	workOrderHandler := rest.NewWorkOrderHandler(workOrderService)
 
 	// create web server with fiber
-	app := fiber.New()
+	// errors returned by handlers are answered as problem details
+	app := fiber.New(fiber.Config{ErrorHandler: rest.ErrorHandler})
 
 	// allows vite to make petitions
-	allowedOrigin := os.Getenv("CORS_ALLOWED_ORIGIN")
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: allowedOrigin,
-		AllowHeaders: "Origin, Content-Type, Accept",
+		AllowOrigins:  cfg.CORS.AllowedOrigin,
+		AllowHeaders:  "Origin, Content-Type, Accept, " + rest.ActorHeader + ", " + rest.IdempotencyHeader + ", " + fiber.HeaderIfMatch,
+		ExposeHeaders: rest.IdempotencyReplayedHeader + ", " + fiber.HeaderETag + ", " + fiber.HeaderLocation,
 	}))
 
+	// retries with the same Idempotency-Key get the first response during IDEMPOTENCY_TTL, a day
+	// by default. A request that did not finish frees the key after IDEMPOTENCY_LOCK_TIMEOUT
+	idempotency := rest.Idempotency(idempotencyStore, cfg.Idempotency.TTL, cfg.Idempotency.LockTimeout)
+
 	// config routes from API, calls handlers
-	rest.SetUpRoutes(app, customerHandler, workOrderHandler)
+	rest.SetUpRoutes(app, customerHandler, workOrderHandler, idempotency)
 
-	// init server
-	port := "3000"
-	log.Printf("Servidor escuchando en el puerto :%s", port)
-	log.Fatal(app.Listen(":" + port))
+	// init server, a Listen error stops everything like a signal
+	log.Printf("Servidor escuchando en el puerto :%s", cfg.Port)
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- app.Listen(":" + cfg.Port)
+		stop()
+	}()
+	<-ctx.Done()
+
+	// requests in progress finish before the relay stops, the events left are sent on the next start
+	if err := app.ShutdownWithTimeout(10 * time.Second); err != nil {
+		log.Printf("Error deteniendo el servidor: %v", err)
+	}
+	stopRelay()
+	relayWG.Wait()
+
+	select {
+	case err := <-listenErr:
+		if err != nil {
+			log.Fatalf("Error en el servidor: %v", err)
+		}
+	default:
+	}
+	log.Println("Servidor detenido.")
+}
 
+// builds the policy of services from the rules of the config
+func newPolicy(rules config.RulesConfig) (services.Policy, error) {
+	return services.PolicyConfig{
+		MaxDuration:  rules.MaxDuration,
+		MinLeadTime:  rules.MinLeadTime,
+		WorkingHours: rules.WorkingHours,
+		Timezone:     rules.Timezone,
+	}.Policy()
 }
@@ -0,0 +1,126 @@
+// cmd/api/migrate.go
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/krud3/prueba-tecnica/internal/adapters/storage"
+	"github.com/krud3/prueba-tecnica/internal/config"
+	"github.com/krud3/prueba-tecnica/migrations"
+)
+
+const migrateUsage = `uso: api migrate <comando>
+  up            aplica todas las migraciones pendientes
+  down [n]      revierte las últimas n migraciones, 1 por defecto
+  goto <v>      aplica o revierte hasta quedar en la versión v (0 revierte todo)
+  force <v>     marca la versión v sin ejecutar nada, para corregir una migración fallida
+  status        lista las migraciones y si están aplicadas
+  check         compara los modelos con las tablas y lista las diferencias`
+
+// runs `api migrate <command>` against the database of the config, the server does not start
+func runMigrate(cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	db, err := storage.NewGormDB(cfg.Storage.Driver, cfg.Database)
+	if err != nil {
+		return err
+	}
+	migrator, err := storage.NewGormMigrator(db, migrations.For(cfg.Storage.Driver))
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch command := args[0]; command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		logMigrations("Aplicada", applied)
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("n inválido: %q, debe ser un número mayor a 0", args[1])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		logMigrations("Revertida", reverted)
+		return err
+	case "goto", "force":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return fmt.Errorf("versión inválida: %q", args[1])
+		}
+		if command == "force" {
+			if err := migrator.Force(ctx, uint(version)); err != nil {
+				return err
+			}
+			log.Printf("Versión marcada como %d.", version)
+			return nil
+		}
+		changed, err := migrator.Goto(ctx, uint(version))
+		logMigrations("Ejecutada", changed)
+		return err
+	case "status":
+		version, dirty, err := migrator.Version(ctx)
+		if err != nil {
+			return err
+		}
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, migration := range status {
+			state := "pendiente"
+			if migration.Applied {
+				state = "aplicada"
+			}
+			fmt.Printf("%03d_%-45s %s\n", migration.Version, migration.Name, state)
+		}
+		fmt.Printf("versión actual: %d, última: %d, dirty: %t\n", version, migrator.Latest(), dirty)
+		return nil
+	case "check":
+		drifts, err := storage.CheckSchema(ctx, db, storage.Models()...)
+		if err != nil {
+			return err
+		}
+		for _, drift := range drifts {
+			fmt.Println(drift)
+		}
+		if len(drifts) > 0 {
+			return fmt.Errorf("el esquema tiene %d diferencias con los modelos", len(drifts))
+		}
+		log.Println("El esquema coincide con los modelos.")
+		return nil
+	default:
+		return errors.New(migrateUsage)
+	}
+}
+
+// applies the pending migrations before the server starts, DB_MIGRATE_ON_START
+func migrateOnStart(migrator *storage.Migrator) {
+	applied, err := migrator.Up(context.Background())
+	logMigrations("Aplicada", applied)
+	if err != nil {
+		log.Fatalf("Error aplicando las migraciones: %v", err)
+	}
+}
+
+func logMigrations(action string, changed []storage.Migration) {
+	for _, migration := range changed {
+		log.Printf("%s la migración %03d_%s", action, migration.Version, migration.Name)
+	}
+	if len(changed) == 0 {
+		log.Println("No hay migraciones para ejecutar.")
+	}
+}
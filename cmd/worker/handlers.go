@@ -0,0 +1,26 @@
+// cmd/worker/handlers.go
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/krud3/prueba-tecnica/internal/core/events"
+)
+
+// logs the event, a message that does not match its schema fails so it ends in the dead
+// letter stream
+type logHandler struct{}
+
+func (logHandler) Handle(ctx context.Context, event string, payload []byte) error {
+	if err := events.Validate(payload); err != nil {
+		return err
+	}
+	envelope, err := events.Decode(payload)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("%s v%d: %s %s", envelope.Type, envelope.SchemaVersion, envelope.AggregateID, envelope.Payload)
+	return nil
+}
@@ -0,0 +1,115 @@
+// cmd/worker/main.go
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/krud3/prueba-tecnica/internal/adapters/cache"
+	"github.com/krud3/prueba-tecnica/internal/adapters/stream"
+	"github.com/krud3/prueba-tecnica/internal/config"
+	"github.com/krud3/prueba-tecnica/internal/core/events"
+)
+
+// reads the events the api publishes, on WORKER_STREAM and the streams of EVENT_STREAMS. Several
+// workers can run at the same time, the consumer group gives each event to only one of them
+func main() {
+
+	// config file, .env and env, stops here if something is missing
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Error cargando la configuración: %v", err)
+	}
+	if err := cfg.ValidateWorker(); err != nil {
+		log.Fatalf("Configuración inválida, revisar el .env como lo dice .env.example:\n%v", err)
+	}
+
+	redisClient, err := cache.NewRedisClient(cfg.Redis)
+	if err != nil {
+		log.Fatalf("Error contectando a Redis: %v", err)
+	}
+	log.Println("Conectado a Redis.")
+
+	// WORKER_STREAM and every stream EVENT_STREAMS sends events to, an event routed to a stream
+	// nobody reads would never be processed or dead lettered
+	streams, err := workerStreams(cfg)
+	if err != nil {
+		log.Fatalf("EVENT_STREAMS inválido: %v", err)
+	}
+
+	// stops on ctrl+c or when docker stops the container
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// one consumer per stream, the first that fails stops the others
+	var wg sync.WaitGroup
+	errs := make([]error, len(streams))
+	for i, s := range streams {
+		consumer := stream.NewRedisStreamConsumer(redisClient, stream.ConsumerConfig{
+			Stream:           s.name,
+			Group:            cfg.Worker.Group,
+			Consumer:         cfg.Worker.Consumer,
+			DeadLetterStream: s.deadLetter,
+			ClaimIdle:        cfg.Worker.ClaimIdle,
+			MaxAttempts:      cfg.Worker.MaxAttempts,
+			Block:            5 * time.Second,
+			BatchSize:        10,
+		})
+		// one handler per event, replace logHandler with the real one when an event needs it
+		for _, eventType := range events.Types() {
+			consumer.Register(eventType, logHandler{})
+		}
+
+		log.Printf("Worker %s leyendo %s en el grupo %s", cfg.Worker.Consumer, s.name, cfg.Worker.Group)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := consumer.Run(ctx); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", s.name, err)
+				stop()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		log.Fatalf("Error en el worker: %v", err)
+	}
+	log.Println("Worker detenido.")
+}
+
+// a stream the worker reads and where the entries that keep failing go
+type consumedStream struct {
+	name       string
+	deadLetter string
+}
+
+// WORKER_STREAM keeps WORKER_DEAD_LETTER_STREAM, the streams of EVENT_STREAMS get <stream>_dead
+func workerStreams(cfg config.Config) ([]consumedStream, error) {
+	routes, err := stream.ParseRoutes(cfg.Events.Streams)
+	if err != nil {
+		return nil, err
+	}
+
+	streams := []consumedStream{{name: cfg.Worker.Stream, deadLetter: cfg.Worker.DeadLetterStream}}
+	seen := map[string]bool{cfg.Worker.Stream: true}
+	// sorted so the logs list them always in the same order
+	routed := slices.Sorted(maps.Values(routes))
+	for _, name := range routed {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		streams = append(streams, consumedStream{name: name, deadLetter: name + "_dead"})
+	}
+	return streams, nil
+}
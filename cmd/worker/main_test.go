@@ -0,0 +1,40 @@
+// cmd/worker/main_test.go
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/krud3/prueba-tecnica/internal/config"
+)
+
+func TestWorkerStreams(t *testing.T) {
+	tests := []struct {
+		name   string
+		routes string
+		want   []consumedStream
+	}{
+		{"no routes", "", []consumedStream{
+			{"work_orders_stream", "work_orders_stream_dead"},
+		}},
+		{"routed streams once each", "customer_created=customers_stream,customer_activated=customers_stream,work_order_created=work_orders_stream", []consumedStream{
+			{"work_orders_stream", "work_orders_stream_dead"},
+			{"customers_stream", "customers_stream_dead"},
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Worker.DeadLetterStream = "work_orders_stream_dead"
+			cfg.Events.Streams = tc.routes
+
+			got, err := workerStreams(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -30,6 +30,12 @@ const docTemplate = `{
                 ],
                 "summary": "Crea un nuevo cliente",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Llave única de la operación, los reintentos con la misma llave reciben la primera respuesta",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
                     {
                         "description": "Datos del Cliente a crear",
                         "name": "customer",
@@ -45,24 +51,40 @@ const docTemplate = `{
                         "description": "Created",
                         "schema": {
                             "$ref": "#/definitions/domain.Customer"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Versión del cliente, usar en If-Match"
+                            },
+                            "Location": {
+                                "type": "string",
+                                "description": "Ruta del cliente creado"
+                            }
                         }
                     },
                     "400": {
                         "description": "Error: Petición inválida",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Error: La petición con el mismo Idempotency-Key sigue en curso",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "422": {
+                        "description": "Error: Idempotency-Key usado con otra petición",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Error: Error interno del servidor",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/rest.ProblemDetails"
                         }
                     }
                 }
@@ -70,7 +92,7 @@ const docTemplate = `{
         },
         "/customers/active": {
             "get": {
-                "description": "Devuelve una lista de todos los clientes cuyo estado es 'is_active = true'.",
+                "description": "Devuelve una página de los clientes cuyo estado es 'is_active = true'. Usar next_cursor como cursor para pedir la siguiente página.",
                 "produces": [
                     "application/json"
                 ],
@@ -78,23 +100,47 @@ const docTemplate = `{
                     "customers"
                 ],
                 "summary": "Obtiene clientes activos",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tamaño de la página (1-200, por defecto 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor devuelto en next_cursor por la página anterior",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "-created_at"
+                        ],
+                        "type": "string",
+                        "description": "Orden por fecha de creación, el único soportado. Otro valor responde 400",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.Customer"
-                            }
+                            "$ref": "#/definitions/rest.PageResponse-domain_Customer"
+                        }
+                    },
+                    "400": {
+                        "description": "Error: Parámetro de paginación inválido",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Error: Error interno del servidor",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/rest.ProblemDetails"
                         }
                     }
                 }
@@ -102,7 +148,7 @@ const docTemplate = `{
         },
         "/customers/all": {
             "get": {
-                "description": "Devuelve una lista de todos los clientes.",
+                "description": "Devuelve una página de todos los clientes. Usar next_cursor como cursor para pedir la siguiente página.",
                 "produces": [
                     "application/json"
                 ],
@@ -110,23 +156,47 @@ const docTemplate = `{
                     "customers"
                 ],
                 "summary": "Obtiene todos los clientes",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tamaño de la página (1-200, por defecto 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor devuelto en next_cursor por la página anterior",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "-created_at"
+                        ],
+                        "type": "string",
+                        "description": "Orden por fecha de creación, el único soportado. Otro valor responde 400",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.Customer"
-                            }
+                            "$ref": "#/definitions/rest.PageResponse-domain_Customer"
+                        }
+                    },
+                    "400": {
+                        "description": "Error: Parámetro de paginación inválido",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Error: Error interno del servidor",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/rest.ProblemDetails"
                         }
                     }
                 }
@@ -134,7 +204,7 @@ const docTemplate = `{
         },
         "/customers/{customerID}/work-orders": {
             "get": {
-                "description": "Obtiene una lista de todas las órdenes de trabajo asociadas a un cliente específico.",
+                "description": "Obtiene una página de las órdenes de trabajo asociadas a un cliente específico. Usar next_cursor como cursor para pedir la siguiente página.",
                 "produces": [
                     "application/json"
                 ],
@@ -150,34 +220,47 @@ const docTemplate = `{
                         "name": "customerID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Tamaño de la página (1-200, por defecto 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor devuelto en next_cursor por la página anterior",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "-created_at"
+                        ],
+                        "type": "string",
+                        "description": "Orden por fecha de creación, el único soportado. Otro valor responde 400",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.WorkOrder"
-                            }
+                            "$ref": "#/definitions/rest.PageResponse-domain_WorkOrder"
                         }
                     },
                     "400": {
-                        "description": "Error: ID de cliente inválido",
+                        "description": "Error: ID de cliente o parámetro de paginación inválido",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/rest.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Error: Error interno del servidor",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/rest.ProblemDetails"
                         }
                     }
                 }
@@ -207,105 +290,102 @@ const docTemplate = `{
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/domain.Customer"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Versión del cliente, usar en If-Match"
+                            }
                         }
                     },
                     "400": {
                         "description": "Error: ID inválido",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/rest.ProblemDetails"
                         }
                     },
                     "404": {
                         "description": "Error: Cliente no encontrado",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/rest.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Error: Error interno del servidor",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/rest.ProblemDetails"
                         }
                     }
                 }
-            }
-        },
-        "/work-orders": {
-            "get": {
-                "description": "Obtiene una lista de órdenes de trabajo. Se puede filtrar por rango de fechas (since, until) y/o por estado (status).",
+            },
+            "delete": {
+                "description": "Borrado lógico del cliente, deja de aparecer en las consultas pero sus órdenes de trabajo se conservan. No se puede eliminar mientras tenga órdenes abiertas (estado 'new').",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "work-orders"
+                    "customers"
                 ],
-                "summary": "Busca órdenes de trabajo con filtros",
+                "summary": "Elimina un cliente",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Fecha de inicio (Formato RFC3339: 2024-07-30T10:00:00Z)",
-                        "name": "since",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Fecha de fin (Formato RFC3339: 2024-07-30T10:00:00Z)",
-                        "name": "until",
-                        "in": "query"
+                        "description": "ID del Cliente (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "enum": [
-                            "new",
-                            "done",
-                            "cancelled"
-                        ],
                         "type": "string",
-                        "description": "Estado de la orden",
-                        "name": "status",
-                        "in": "query"
+                        "description": "ETag del cliente leído antes, si cambió se responde 412",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.WorkOrder"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     },
                     "400": {
-                        "description": "Error: Parámetro de filtro inválido",
+                        "description": "Error: ID inválido",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Error: Cliente no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Error: El cliente tiene órdenes abiertas o cambió mientras se eliminaba",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "412": {
+                        "description": "Error: If-Match no coincide con la versión actual",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Error: Error interno del servidor",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/rest.ProblemDetails"
                         }
                     }
                 }
             },
-            "post": {
-                "description": "Crea una nueva orden para un cliente. Valida reglas de negocio como el estado del cliente y el intervalo de fechas.",
+            "patch": {
+                "description": "Cambia nombres y/o dirección de un cliente. Solo se modifican los campos enviados, el estado (activo, fechas) solo cambia con órdenes de trabajo.",
                 "consumes": [
                     "application/json"
                 ],
@@ -313,80 +393,93 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "work-orders"
+                    "customers"
                 ],
-                "summary": "Crea una nueva orden de trabajo",
+                "summary": "Actualiza un cliente",
                 "parameters": [
                     {
-                        "description": "Datos de la Orden de Trabajo a crear",
-                        "name": "workOrder",
+                        "type": "string",
+                        "description": "ID del Cliente (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag del cliente leído antes, si cambió se responde 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Campos del Cliente a modificar",
+                        "name": "customer",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/rest.CreateWorkOrderRequest"
+                            "$ref": "#/definitions/rest.UpdateCustomerRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.WorkOrder"
+                            "$ref": "#/definitions/domain.Customer"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Nueva versión del cliente"
+                            }
                         }
                     },
                     "400": {
                         "description": "Error: Petición inválida",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/rest.ProblemDetails"
                         }
                     },
                     "404": {
                         "description": "Error: Cliente no encontrado",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/rest.ProblemDetails"
                         }
                     },
                     "409": {
-                        "description": "Error: Conflicto de negocio (ej. cliente ya activo)",
+                        "description": "Error: El cliente cambió mientras se actualizaba",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "412": {
+                        "description": "Error: If-Match no coincide con la versión actual",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Error: Error interno del servidor",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/rest.ProblemDetails"
                         }
                     }
                 }
             }
         },
-        "/work-orders/{id}": {
+        "/customers/{id}/service-periods": {
             "get": {
-                "description": "Obtiene los detalles de una orden de trabajo, incluyendo la información del cliente embebida.",
+                "description": "Devuelve los periodos de servicio del cliente del más antiguo al más reciente, cada uno abierto por una orden de activación y cerrado por una de cancelación. EndedAt es null en el periodo actual. active_days suma los días completos de todos los periodos, el actual cuenta hasta ahora.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "work-orders"
+                    "customers"
                 ],
-                "summary": "Busca una orden de trabajo por ID",
+                "summary": "Historial de servicio de un cliente",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID de la Orden de Trabajo (UUID)",
+                        "description": "ID del Cliente (UUID)",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -396,102 +489,682 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.WorkOrder"
+                            "$ref": "#/definitions/rest.CustomerServicePeriodsResponse"
                         }
                     },
                     "400": {
                         "description": "Error: ID inválido",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/rest.ProblemDetails"
                         }
                     },
                     "404": {
-                        "description": "Error: Orden no encontrada",
+                        "description": "Error: Cliente no encontrado",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/rest.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Error: Error interno del servidor",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/rest.ProblemDetails"
                         }
                     }
                 }
             }
         },
-        "/work-orders/{id}/complete": {
-            "patch": {
-                "description": "Marca una orden como 'done', lo que activa/desactiva al cliente asociado y envía un evento a Redis.",
+        "/work-orders": {
+            "get": {
+                "description": "Obtiene una página de órdenes de trabajo. Se puede filtrar por rango de fechas planeadas (since, until), estado (status), cliente (customerID), tipo (type), texto en la descripción (q) y rango de fecha de creación (createdSince, createdUntil). Los filtros se combinan entre sí. Usar next_cursor como cursor para pedir la siguiente página.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "work-orders"
                 ],
-                "summary": "Completa una orden de trabajo",
+                "summary": "Busca órdenes de trabajo con filtros",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID de la Orden de Trabajo (UUID)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "400": {
-                        "description": "Error: ID inválido",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
+                        "description": "Fecha de inicio (Formato RFC3339: 2024-07-30T10:00:00Z)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fecha de fin (Formato RFC3339: 2024-07-30T10:00:00Z)",
+                        "name": "until",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "new",
+                            "done",
+                            "cancelled"
+                        ],
+                        "type": "string",
+                        "description": "Estado de la orden",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID del Cliente (UUID)",
+                        "name": "customerID",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "activar cliente",
+                            "cancelar cliente"
+                        ],
+                        "type": "string",
+                        "description": "Tipo de la orden",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Texto a buscar en la descripción (sin distinguir mayúsculas)",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Creada desde (Formato RFC3339: 2024-07-30T10:00:00Z)",
+                        "name": "createdSince",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Creada hasta (Formato RFC3339: 2024-07-30T10:00:00Z)",
+                        "name": "createdUntil",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Tamaño de la página (1-200, por defecto 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor devuelto en next_cursor por la página anterior",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "-created_at"
+                        ],
+                        "type": "string",
+                        "description": "Orden por fecha de creación, el único soportado. Otro valor responde 400",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.PageResponse-domain_WorkOrder"
+                        }
+                    },
+                    "400": {
+                        "description": "Error: Parámetro de filtro inválido",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Error: Error interno del servidor",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Crea una nueva orden para un cliente. Valida reglas de negocio como el estado del cliente y las reglas de fechas configuradas (duración máxima, anticipación mínima y horario laboral).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Crea una nueva orden de trabajo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Llave única de la operación, los reintentos con la misma llave reciben la primera respuesta",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Usuario que crea la orden, queda en el historial",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Datos de la Orden de Trabajo a crear",
+                        "name": "workOrder",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.CreateWorkOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WorkOrder"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Versión de la orden, usar en If-Match"
+                            },
+                            "Location": {
+                                "type": "string",
+                                "description": "Ruta de la orden creada"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Error: Petición inválida",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Error: Cliente no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Error: Conflicto de negocio (ej. cliente ya activo, ya tiene una orden abierta del mismo tipo o en el mismo horario)",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "422": {
+                        "description": "Error: Idempotency-Key usado con otra petición",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Error: Error interno del servidor",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/conflicts": {
+            "get": {
+                "description": "Devuelve los pares de órdenes abiertas ('new') del mismo cliente cuyas fechas planeadas se cruzan y que caen dentro del rango [since, until). El rango no puede ser mayor a 31 días.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Lista órdenes de trabajo con horarios cruzados",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Inicio del rango (Formato RFC3339: 2024-07-30T10:00:00Z)",
+                        "name": "since",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fin del rango (Formato RFC3339: 2024-07-30T10:00:00Z)",
+                        "name": "until",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID del Cliente (UUID)",
+                        "name": "customerID",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.WorkOrderConflictsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Error: Rango o parámetro inválido",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Error: Error interno del servidor",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}": {
+            "get": {
+                "description": "Obtiene los detalles de una orden de trabajo, incluyendo la información del cliente embebida.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Busca una orden de trabajo por ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID de la Orden de Trabajo (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WorkOrder"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Versión de la orden, usar en If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Error: ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
                     },
                     "404": {
                         "description": "Error: Orden no encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Error: Error interno del servidor",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Cambia la descripción y/o las fechas planeadas de una orden en estado 'new'. Si cambian las fechas valida de nuevo las reglas de negocio configuradas (duración máxima, anticipación mínima y horario laboral) y registra quién hizo cada cambio (header X-Actor).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Modifica o reprograma una orden de trabajo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID de la Orden de Trabajo (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Usuario que hace el cambio",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag de la orden leída antes, si cambió se responde 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Campos de la Orden de Trabajo a modificar",
+                        "name": "workOrder",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.UpdateWorkOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WorkOrder"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Nueva versión de la orden"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Error: Petición inválida",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Error: Orden no encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Error: Conflicto de negocio (ej. la orden ya está completada o cancelada, o se cruza con otra orden abierta)",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "412": {
+                        "description": "Error: If-Match no coincide con la versión actual",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Error: Error interno del servidor",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/cancel": {
+            "patch": {
+                "description": "Marca una orden como 'cancelled' guardando el motivo y envía un evento a Redis. El cliente asociado no cambia. El cambio queda en el historial de la orden.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Cancela una orden de trabajo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID de la Orden de Trabajo (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Usuario que cancela la orden, queda en el historial",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag de la orden leída antes, si cambió se responde 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Motivo de la cancelación",
+                        "name": "cancellation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.CancelWorkOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Nueva versión de la orden"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Error: ID o motivo inválido",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Error: Orden no encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Error: Conflicto de estado (ej. la orden ya está completada o cancelada)",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "412": {
+                        "description": "Error: If-Match no coincide con la versión actual",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Error: Error interno del servidor",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/changes": {
+            "get": {
+                "description": "Devuelve los cambios hechos con PATCH /work-orders/{id} del más antiguo al más reciente, un registro por campo: el campo (description, planned_date_begin o planned_date_end), el valor anterior, el nuevo, quién lo cambió (header X-Actor) y cuándo. Las fechas van en RFC3339.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Cambios de campos de una orden de trabajo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID de la Orden de Trabajo (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.WorkOrderChangesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Error: ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Error: Orden no encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Error: Error interno del servidor",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/complete": {
+            "patch": {
+                "description": "Marca una orden como 'done', lo que activa/desactiva al cliente asociado y envía un evento a Redis. El cambio queda en el historial de la orden.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Completa una orden de trabajo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID de la Orden de Trabajo (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Usuario que completa la orden, queda en el historial",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag de la orden leída antes, si cambió se responde 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Nueva versión de la orden"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Error: ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Error: Orden no encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
                         }
                     },
                     "409": {
-                        "description": "Error: Conflicto de estado (ej. la orden ya está completada)",
+                        "description": "Error: Conflicto de estado (ej. la orden ya está completada o el cliente ya está en el estado de la orden)",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "412": {
+                        "description": "Error: If-Match no coincide con la versión actual",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Error: Error interno del servidor",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/history": {
+            "get": {
+                "description": "Devuelve los cambios de estado de la orden del más antiguo al más reciente: quién la creó, completó o canceló y cuándo. OldStatus es null en la creación.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Historial de estados de una orden de trabajo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID de la Orden de Trabajo (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.WorkOrderHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Error: ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Error: Orden no encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Error: Error interno del servidor",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemDetails"
                         }
                     }
                 }
@@ -508,6 +1181,11 @@ const docTemplate = `{
                 "createdAt": {
                     "type": "string"
                 },
+                "deletedAt": {
+                    "description": "borrado lógico, gorm oculta los clientes con deleted_at en todas las consultas",
+                    "type": "string",
+                    "format": "date-time"
+                },
                 "endDate": {
                     "type": "string"
                 },
@@ -527,6 +1205,10 @@ const docTemplate = `{
                     "description": "puntero para poder capturar el nil",
                     "type": "string"
                 },
+                "version": {
+                    "description": "sube en cada actualización, control de concurrencia optimista",
+                    "type": "integer"
+                },
                 "workOrders": {
                     "type": "array",
                     "items": {
@@ -535,6 +1217,45 @@ const docTemplate = `{
                 }
             }
         },
+        "domain.CustomerServicePeriod": {
+            "type": "object",
+            "properties": {
+                "customerID": {
+                    "type": "string"
+                },
+                "endWorkOrderID": {
+                    "type": "string"
+                },
+                "endedAt": {
+                    "description": "nil mientras el cliente sigue activo",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "startWorkOrderID": {
+                    "description": "órdenes que abrieron y cerraron el periodo, nil en los periodos anteriores a la migración 013",
+                    "type": "string"
+                },
+                "startedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
         "domain.Status": {
             "type": "string",
             "enum": [
@@ -562,6 +1283,10 @@ const docTemplate = `{
         "domain.WorkOrder": {
             "type": "object",
             "properties": {
+                "cancellationReason": {
+                    "description": "solo se llena cuando la orden se cancela",
+                    "type": "string"
+                },
                 "createdAt": {
                     "type": "string"
                 },
@@ -598,6 +1323,75 @@ const docTemplate = `{
                             "$ref": "#/definitions/domain.Type"
                         }
                     ]
+                },
+                "version": {
+                    "description": "sube en cada actualización, control de concurrencia optimista",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.WorkOrderChangeLog": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "newValue": {
+                    "type": "string"
+                },
+                "oldValue": {
+                    "type": "string"
+                },
+                "workOrderID": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WorkOrderStatusChange": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "newStatus": {
+                    "$ref": "#/definitions/domain.Status"
+                },
+                "oldStatus": {
+                    "description": "nil cuando la orden se crea",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Status"
+                        }
+                    ]
+                },
+                "workOrderID": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.CancelWorkOrderRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string"
                 }
             }
         },
@@ -608,15 +1402,23 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "firstName": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 255
                 },
                 "lastName": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 255
                 }
             }
         },
         "rest.CreateWorkOrderRequest": {
             "type": "object",
+            "required": [
+                "customerID",
+                "plannedDateBegin",
+                "plannedDateEnd",
+                "type"
+            ],
             "properties": {
                 "customerID": {
                     "type": "string"
@@ -631,7 +1433,186 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "type": {
-                    "$ref": "#/definitions/domain.Type"
+                    "enum": [
+                        "activar cliente",
+                        "cancelar cliente"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Type"
+                        }
+                    ]
+                }
+            }
+        },
+        "rest.CustomerServicePeriodsResponse": {
+            "type": "object",
+            "properties": {
+                "active_days": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.CustomerServicePeriod"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "rest.PageResponse-domain_Customer": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Customer"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "rest.PageResponse-domain_WorkOrder": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.WorkOrder"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "rest.ProblemDetails": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "one entry per invalid field, only when the code is invalid_fields",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string"
+                },
+                "limit": {
+                    "type": "string"
+                },
+                "rule": {
+                    "description": "business rule that failed and its configured limit, ej. max_duration and 2h",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.UpdateCustomerRequest": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "firstName": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "lastName": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "rest.UpdateWorkOrderRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "plannedDateBegin": {
+                    "type": "string"
+                },
+                "plannedDateEnd": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.WorkOrderChangesResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.WorkOrderChangeLog"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "rest.WorkOrderConflictResponse": {
+            "type": "object",
+            "properties": {
+                "conflicts_with": {
+                    "$ref": "#/definitions/domain.WorkOrder"
+                },
+                "work_order": {
+                    "$ref": "#/definitions/domain.WorkOrder"
+                }
+            }
+        },
+        "rest.WorkOrderConflictsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rest.WorkOrderConflictResponse"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "rest.WorkOrderHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.WorkOrderStatusChange"
+                    }
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         }
@@ -0,0 +1,28 @@
+// internal/adapters/cache/redis.go
+
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/krud3/prueba-tecnica/internal/config"
+)
+
+// NewRedisClient connects to cfg.Addr and checks that redis answers, the api and the worker
+// share it
+func NewRedisClient(cfg config.RedisConfig) (*redis.Client, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr: cfg.Addr,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// test conection with redis
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return client, nil
+}
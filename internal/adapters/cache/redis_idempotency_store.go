@@ -0,0 +1,164 @@
+// internal/adapters/cache/redis_idempotency_store.go
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+)
+
+// prefix of the redis keys, keeps them apart from anything else in the same database
+const idempotencyPrefix = "idempotency:"
+
+// the key is held by another request, whileHeld does nothing
+var errNotHeld = errors.New("idempotency key held by another request")
+
+type redisIdempotencyStore struct {
+	client *redis.Client
+}
+
+// keys are saved as json and redis deletes them when they expire
+func NewRedisIdempotencyStore(client *redis.Client) ports.IdempotencyStore {
+	return &redisIdempotencyStore{client: client}
+}
+
+func (s *redisIdempotencyStore) Reserve(ctx context.Context, key domain.IdempotencyKey) (*domain.IdempotencyKey, bool, error) {
+	key.CreatedAt = time.Now()
+	data, err := json.Marshal(key)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// SET NX, only one of two retries arriving together gets the key
+	reserved, err := s.client.SetNX(ctx, idempotencyPrefix+key.Key, data, time.Until(key.ExpiresAt)).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if reserved {
+		return &key, true, nil
+	}
+
+	stored, err := s.get(ctx, s.client, key.Key)
+	// expired between SETNX and GET, try again
+	if errors.Is(err, redis.Nil) {
+		return s.Reserve(ctx, key)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if stored.Abandoned(time.Now()) {
+		taken, err := s.takeOver(ctx, key, data)
+		if err != nil {
+			return nil, false, err
+		}
+		if taken {
+			return &key, true, nil
+		}
+		// another retry changed it first, read it again
+		return s.Reserve(ctx, key)
+	}
+	return stored, false, nil
+}
+
+// replaces an abandoned key with key, WATCH makes it fail if the key changes in between
+func (s *redisIdempotencyStore) takeOver(ctx context.Context, key domain.IdempotencyKey, data []byte) (bool, error) {
+	redisKey := idempotencyPrefix + key.Key
+	taken := false
+
+	err := s.client.Watch(ctx, func(tx *redis.Tx) error {
+		stored, err := s.get(ctx, tx, key.Key)
+		if err != nil {
+			return err
+		}
+		if !stored.Abandoned(time.Now()) {
+			return nil
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, redisKey, data, time.Until(key.ExpiresAt))
+			return nil
+		})
+		taken = err == nil
+		return err
+	}, redisKey)
+
+	// changed or expired meanwhile, the caller reads it again
+	if errors.Is(err, redis.TxFailedErr) || errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	return taken, err
+}
+
+func (s *redisIdempotencyStore) Complete(ctx context.Context, reserved domain.IdempotencyKey, statusCode int, contentType string, headers map[string]string, body []byte) (bool, error) {
+	return s.whileHeld(ctx, reserved, func(pipe redis.Pipeliner, stored *domain.IdempotencyKey) error {
+		now := time.Now()
+		stored.StatusCode = statusCode
+		stored.ContentType = contentType
+		stored.Headers = headers
+		stored.Body = body
+		stored.CompletedAt = &now
+		data, err := json.Marshal(stored)
+		if err != nil {
+			return err
+		}
+		// KEEPTTL updates the key without moving its expiration
+		pipe.SetArgs(ctx, idempotencyPrefix+reserved.Key, data, redis.SetArgs{KeepTTL: true})
+		return nil
+	})
+}
+
+func (s *redisIdempotencyStore) Release(ctx context.Context, reserved domain.IdempotencyKey) error {
+	_, err := s.whileHeld(ctx, reserved, func(pipe redis.Pipeliner, stored *domain.IdempotencyKey) error {
+		pipe.Del(ctx, idempotencyPrefix+reserved.Key)
+		return nil
+	})
+	return err
+}
+
+// runs fn in a transaction when the key is still held by reserved and returns true, WATCH makes
+// it fail if a retry takes it over in between. Nothing runs when it expired or is held by
+// another request
+func (s *redisIdempotencyStore) whileHeld(ctx context.Context, reserved domain.IdempotencyKey, fn func(pipe redis.Pipeliner, stored *domain.IdempotencyKey) error) (bool, error) {
+	redisKey := idempotencyPrefix + reserved.Key
+	done := false
+
+	err := s.client.Watch(ctx, func(tx *redis.Tx) error {
+		stored, err := s.get(ctx, tx, reserved.Key)
+		if err != nil {
+			return err
+		}
+		if !stored.HeldBy(reserved) {
+			return errNotHeld
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			return fn(pipe, stored)
+		})
+		done = err == nil
+		return err
+	}, redisKey)
+
+	// expired, taken over or changed meanwhile, it is no longer this request's key
+	if errors.Is(err, redis.Nil) || errors.Is(err, redis.TxFailedErr) || errors.Is(err, errNotHeld) {
+		return false, nil
+	}
+	return done, err
+}
+
+// client is the redis client or the transaction of a WATCH
+func (s *redisIdempotencyStore) get(ctx context.Context, client redis.Cmdable, key string) (*domain.IdempotencyKey, error) {
+	data, err := client.Get(ctx, idempotencyPrefix+key).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var stored domain.IdempotencyKey
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
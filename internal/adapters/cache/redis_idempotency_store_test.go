@@ -0,0 +1,20 @@
+// internal/adapters/cache/redis_idempotency_store_test.go
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/krud3/prueba-tecnica/internal/adapters/repotest"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+)
+
+func TestRedisIdempotencyStore(t *testing.T) {
+	repotest.RunIdempotencyStore(t, func(t *testing.T) ports.IdempotencyStore {
+		client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+		t.Cleanup(func() { client.Close() })
+		return NewRedisIdempotencyStore(client)
+	})
+}
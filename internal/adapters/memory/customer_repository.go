@@ -0,0 +1,129 @@
+// internal/adapters/memory/customer_repository.go
+
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNoCID = domain.NewValidationError("customer_id_required", "no se encontró ID asociada al customer")
+)
+
+type memoryCustomerRepository struct {
+	db *DB
+}
+
+func NewMemoryCustomerRepository(db *DB) ports.CustomerRepository {
+	return &memoryCustomerRepository{db: db}
+}
+
+func (r *memoryCustomerRepository) Create(ctx context.Context, customer domain.Customer) error {
+	//uuid if not exist
+	if customer.ID == uuid.Nil {
+		customer.ID = uuid.New()
+	}
+	// same as autoCreateTime
+	if customer.CreatedAt.IsZero() {
+		customer.CreatedAt = time.Now()
+	}
+	// same default the table has
+	if customer.Version == 0 {
+		customer.Version = 1
+	}
+	// relations are never stored, gorm does not preload them for customers either
+	customer.WorkOrders = nil
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	r.db.customers[customer.ID] = customer
+	return nil
+}
+
+func (r *memoryCustomerRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Customer, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	customer, ok := r.db.customers[id]
+	if !ok || customer.DeletedAt.Valid {
+		// same error as gorm repository
+		return nil, domain.ErrCustomerNotFound
+	}
+	// founded
+	return &customer, nil
+}
+
+func (r *memoryCustomerRepository) GetActive(ctx context.Context, pagination ports.Pagination) (ports.Page[domain.Customer], error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var customers []domain.Customer
+	for _, customer := range r.db.customers {
+		if customer.IsActive && !customer.DeletedAt.Valid {
+			customers = append(customers, customer)
+		}
+	}
+
+	return paginate(customers, pagination, customerCursor), nil
+}
+
+func (r *memoryCustomerRepository) GetAll(ctx context.Context, pagination ports.Pagination) (ports.Page[domain.Customer], error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var customers []domain.Customer
+	for _, customer := range r.db.customers {
+		// soft deleted customers are hidden like gorm does
+		if !customer.DeletedAt.Valid {
+			customers = append(customers, customer)
+		}
+	}
+
+	return paginate(customers, pagination, customerCursor), nil
+}
+
+func (r *memoryCustomerRepository) Update(ctx context.Context, customer domain.Customer) error {
+	if customer.ID == uuid.Nil {
+		return ErrNoCID
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	stored, ok := r.db.customers[customer.ID]
+	// same as the WHERE version = ? of the gorm repository, deleted rows do not match either
+	if !ok || stored.DeletedAt.Valid || stored.Version != customer.Version {
+		return domain.ErrVersionConflict
+	}
+	customer.Version++
+	customer.CreatedAt = stored.CreatedAt
+	customer.WorkOrders = nil
+	r.db.customers[customer.ID] = customer
+	return nil
+}
+
+func (r *memoryCustomerRepository) Delete(ctx context.Context, id uuid.UUID, version int) error {
+	if id == uuid.Nil {
+		return ErrNoCID
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	customer, ok := r.db.customers[id]
+	// same as Update, deleted rows do not match either
+	if !ok || customer.DeletedAt.Valid || customer.Version != version {
+		return domain.ErrVersionConflict
+	}
+	// soft delete, same as gorm.DeletedAt
+	customer.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	r.db.customers[id] = customer
+	return nil
+}
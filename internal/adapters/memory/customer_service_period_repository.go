@@ -0,0 +1,73 @@
+// internal/adapters/memory/customer_service_period_repository.go
+
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+)
+
+type memoryCustomerServicePeriodRepository struct {
+	db *DB
+}
+
+func NewMemoryCustomerServicePeriodRepository(db *DB) ports.CustomerServicePeriodRepository {
+	return &memoryCustomerServicePeriodRepository{db: db}
+}
+
+func (r *memoryCustomerServicePeriodRepository) Open(ctx context.Context, period domain.CustomerServicePeriod) error {
+	if period.ID == uuid.Nil {
+		period.ID = uuid.New()
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	// same as the foreign key and the unique index of the table
+	if _, ok := r.db.customers[period.CustomerID]; !ok {
+		return domain.ErrCustomerNotFound
+	}
+	for _, existing := range r.db.periods {
+		if existing.CustomerID == period.CustomerID && existing.EndedAt == nil {
+			return domain.ErrDuplicated
+		}
+	}
+	r.db.periods = append(r.db.periods, period)
+	return nil
+}
+
+func (r *memoryCustomerServicePeriodRepository) Close(ctx context.Context, customerID uuid.UUID, endedAt time.Time, workOrderID uuid.UUID) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for i, period := range r.db.periods {
+		if period.CustomerID == customerID && period.EndedAt == nil {
+			r.db.periods[i].EndedAt = &endedAt
+			r.db.periods[i].EndWorkOrderID = &workOrderID
+		}
+	}
+	return nil
+}
+
+func (r *memoryCustomerServicePeriodRepository) FindByCustomerID(ctx context.Context, customerID uuid.UUID) ([]domain.CustomerServicePeriod, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	periods := []domain.CustomerServicePeriod{}
+	for _, period := range r.db.periods {
+		if period.CustomerID == customerID {
+			periods = append(periods, period)
+		}
+	}
+	// same order as the table
+	sort.SliceStable(periods, func(i, j int) bool {
+		return periods[i].StartedAt.Before(periods[j].StartedAt)
+	})
+
+	return periods, nil
+}
@@ -0,0 +1,31 @@
+// internal/adapters/memory/db.go
+package memory
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+)
+
+// DB plays the role of *gorm.DB for the in-memory repositories, all of them share it so
+// work orders can preload their customer
+type DB struct {
+	mu         sync.RWMutex
+	txMu       sync.Mutex // held while a unit of work runs
+	customers  map[uuid.UUID]domain.Customer
+	workOrders map[uuid.UUID]domain.WorkOrder
+	outbox     []domain.OutboxMessage
+	changeLogs []domain.WorkOrderChangeLog
+	// status history of the work orders, oldest first
+	statusChanges []domain.WorkOrderStatusChange
+	// active periods of the customers, oldest first
+	periods []domain.CustomerServicePeriod
+}
+
+func NewDB() *DB {
+	return &DB{
+		customers:  make(map[uuid.UUID]domain.Customer),
+		workOrders: make(map[uuid.UUID]domain.WorkOrder),
+	}
+}
@@ -0,0 +1,44 @@
+// internal/adapters/memory/event_publisher.go
+
+package memory
+
+import (
+	"context"
+	"sync"
+)
+
+type PublishedEvent struct {
+	Event   string
+	Payload []byte
+}
+
+// RecordingPublisher keeps every published event instead of sending it, useful for tests
+// and to run without redis
+type RecordingPublisher struct {
+	mu     sync.Mutex
+	events []PublishedEvent
+}
+
+func NewRecordingPublisher() *RecordingPublisher {
+	return &RecordingPublisher{}
+}
+
+func (p *RecordingPublisher) Publish(ctx context.Context, event string, payload []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// copy so callers can reuse their buffer
+	p.events = append(p.events, PublishedEvent{
+		Event:   event,
+		Payload: append([]byte(nil), payload...),
+	})
+	return nil
+}
+
+// Events returns the published events in order
+func (p *RecordingPublisher) Events() []PublishedEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]PublishedEvent(nil), p.events...)
+}
@@ -0,0 +1,77 @@
+// internal/adapters/memory/idempotency_store.go
+
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+)
+
+// keys live only in this process, retries that reach another instance run again
+type memoryIdempotencyStore struct {
+	mu   sync.Mutex
+	keys map[string]domain.IdempotencyKey
+}
+
+func NewMemoryIdempotencyStore() ports.IdempotencyStore {
+	return &memoryIdempotencyStore{keys: make(map[string]domain.IdempotencyKey)}
+}
+
+func (s *memoryIdempotencyStore) Reserve(ctx context.Context, key domain.IdempotencyKey) (*domain.IdempotencyKey, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if stored, ok := s.keys[key.Key]; ok && stored.ExpiresAt.After(now) && !stored.Abandoned(now) {
+		return &stored, false, nil
+	}
+
+	// expired keys are dropped here, nothing else reads them
+	s.dropExpired(now)
+
+	key.CreatedAt = now
+	key.CompletedAt = nil
+	s.keys[key.Key] = key
+	return &key, true, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(ctx context.Context, reserved domain.IdempotencyKey, statusCode int, contentType string, headers map[string]string, body []byte) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.keys[reserved.Key]
+	if !ok || !stored.HeldBy(reserved) {
+		return false, nil
+	}
+	now := time.Now()
+	stored.StatusCode = statusCode
+	stored.ContentType = contentType
+	stored.Headers = headers
+	stored.Body = body
+	stored.CompletedAt = &now
+	s.keys[reserved.Key] = stored
+	return true, nil
+}
+
+func (s *memoryIdempotencyStore) Release(ctx context.Context, reserved domain.IdempotencyKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stored, ok := s.keys[reserved.Key]; ok && stored.HeldBy(reserved) {
+		delete(s.keys, reserved.Key)
+	}
+	return nil
+}
+
+// callers must hold the lock
+func (s *memoryIdempotencyStore) dropExpired(now time.Time) {
+	for k, stored := range s.keys {
+		if !stored.ExpiresAt.After(now) {
+			delete(s.keys, k)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// internal/adapters/memory/outbox_repository.go
+
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+)
+
+type memoryOutboxRepository struct {
+	db *DB
+}
+
+func NewMemoryOutboxRepository(db *DB) ports.OutboxRepository {
+	return &memoryOutboxRepository{db: db}
+}
+
+func (r *memoryOutboxRepository) Create(ctx context.Context, message domain.OutboxMessage) error {
+	if message.ID == uuid.Nil {
+		message.ID = uuid.New()
+	}
+	if message.CreatedAt.IsZero() {
+		message.CreatedAt = time.Now()
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	r.db.outbox = append(r.db.outbox, message)
+	return nil
+}
+
+func (r *memoryOutboxRepository) FindPending(ctx context.Context, limit int) ([]domain.OutboxMessage, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	// outbox is appended in order, oldest first already
+	var messages []domain.OutboxMessage
+	for _, message := range r.db.outbox {
+		if len(messages) == limit {
+			break
+		}
+		if message.PublishedAt == nil {
+			messages = append(messages, message)
+		}
+	}
+
+	return messages, nil
+}
+
+func (r *memoryOutboxRepository) MarkPublished(ctx context.Context, id uuid.UUID) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for i := range r.db.outbox {
+		if r.db.outbox[i].ID == id {
+			now := time.Now()
+			r.db.outbox[i].PublishedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *memoryOutboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, reason string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for i := range r.db.outbox {
+		if r.db.outbox[i].ID == id {
+			r.db.outbox[i].Attempts++
+			r.db.outbox[i].LastError = reason
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+// internal/adapters/memory/pagination.go
+
+package memory
+
+import (
+	"sort"
+
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+)
+
+// cuts one page of rows the same way the gorm repositories do, keyset on (created_at, id)
+func paginate[T any](rows []T, pagination ports.Pagination, key func(T) ports.Cursor) ports.Page[T] {
+	desc := pagination.Sort == ports.SortDesc
+
+	sort.Slice(rows, func(i, j int) bool {
+		if desc {
+			return cursorLess(key(rows[j]), key(rows[i]))
+		}
+		return cursorLess(key(rows[i]), key(rows[j]))
+	})
+
+	page := ports.Page[T]{Total: int64(len(rows))}
+
+	// skip everything until the cursor
+	start := 0
+	if pagination.Cursor != nil {
+		start = sort.Search(len(rows), func(i int) bool {
+			if desc {
+				return cursorLess(key(rows[i]), *pagination.Cursor)
+			}
+			return cursorLess(*pagination.Cursor, key(rows[i]))
+		})
+	}
+	rows = rows[start:]
+
+	if pagination.Limit > 0 && len(rows) > pagination.Limit {
+		rows = rows[:pagination.Limit]
+		next := key(rows[len(rows)-1])
+		page.NextCursor = &next
+	}
+	page.Items = rows
+	return page
+}
+
+// same order as ORDER BY created_at, id
+func cursorLess(a, b ports.Cursor) bool {
+	if a.CreatedAt.Equal(b.CreatedAt) {
+		return a.ID.String() < b.ID.String()
+	}
+	return a.CreatedAt.Before(b.CreatedAt)
+}
+
+func customerCursor(customer domain.Customer) ports.Cursor {
+	return ports.Cursor{CreatedAt: customer.CreatedAt, ID: customer.ID}
+}
+
+func workOrderCursor(workOrder domain.WorkOrder) ports.Cursor {
+	return ports.Cursor{CreatedAt: workOrder.CreatedAt, ID: workOrder.ID}
+}
@@ -0,0 +1,27 @@
+// internal/adapters/memory/repository_test.go
+
+package memory
+
+import (
+	"testing"
+
+	"github.com/krud3/prueba-tecnica/internal/adapters/repotest"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+)
+
+// the memory repositories stand in for the database, they must answer as storage does
+func TestRepositories(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		db := NewDB()
+		return repotest.Repositories{
+			Customers:  NewMemoryCustomerRepository(db),
+			WorkOrders: NewMemoryWorkOrderRepository(db),
+		}
+	})
+}
+
+func TestIdempotencyStore(t *testing.T) {
+	repotest.RunIdempotencyStore(t, func(t *testing.T) ports.IdempotencyStore {
+		return NewMemoryIdempotencyStore()
+	})
+}
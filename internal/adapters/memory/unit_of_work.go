@@ -0,0 +1,84 @@
+// internal/adapters/memory/unit_of_work.go
+
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+)
+
+type memoryUnitOfWork struct {
+	db *DB
+}
+
+func NewMemoryUnitOfWork(db *DB) ports.UnitOfWork {
+	return &memoryUnitOfWork{db: db}
+}
+
+// Do runs units of work one at a time and restores a snapshot of the data if fn fails, writes
+// made outside a unit of work while it runs are lost on rollback, good enough without a database
+func (u *memoryUnitOfWork) Do(ctx context.Context, fn func(repos ports.TxRepositories) error) error {
+	u.db.txMu.Lock()
+	defer u.db.txMu.Unlock()
+
+	snapshot := u.db.snapshot()
+
+	err := fn(ports.TxRepositories{
+		Customers:     NewMemoryCustomerRepository(u.db),
+		WorkOrders:    NewMemoryWorkOrderRepository(u.db),
+		Outbox:        NewMemoryOutboxRepository(u.db),
+		ChangeLogs:    NewMemoryWorkOrderChangeLogRepository(u.db),
+		StatusChanges: NewMemoryWorkOrderStatusChangeRepository(u.db),
+		Periods:       NewMemoryCustomerServicePeriodRepository(u.db),
+	})
+	if err != nil {
+		// rollback
+		u.db.restore(snapshot)
+	}
+	return err
+}
+
+type dbSnapshot struct {
+	customers     map[uuid.UUID]domain.Customer
+	workOrders    map[uuid.UUID]domain.WorkOrder
+	outbox        []domain.OutboxMessage
+	changeLogs    []domain.WorkOrderChangeLog
+	statusChanges []domain.WorkOrderStatusChange
+	periods       []domain.CustomerServicePeriod
+}
+
+func (db *DB) snapshot() dbSnapshot {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	s := dbSnapshot{
+		customers:     make(map[uuid.UUID]domain.Customer, len(db.customers)),
+		workOrders:    make(map[uuid.UUID]domain.WorkOrder, len(db.workOrders)),
+		outbox:        append([]domain.OutboxMessage(nil), db.outbox...),
+		changeLogs:    append([]domain.WorkOrderChangeLog(nil), db.changeLogs...),
+		statusChanges: append([]domain.WorkOrderStatusChange(nil), db.statusChanges...),
+		periods:       append([]domain.CustomerServicePeriod(nil), db.periods...),
+	}
+	for id, customer := range db.customers {
+		s.customers[id] = customer
+	}
+	for id, workOrder := range db.workOrders {
+		s.workOrders[id] = workOrder
+	}
+	return s
+}
+
+func (db *DB) restore(s dbSnapshot) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.customers = s.customers
+	db.workOrders = s.workOrders
+	db.outbox = s.outbox
+	db.changeLogs = s.changeLogs
+	db.statusChanges = s.statusChanges
+	db.periods = s.periods
+}
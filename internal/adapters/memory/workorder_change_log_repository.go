@@ -0,0 +1,50 @@
+// internal/adapters/memory/workorder_change_log_repository.go
+
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+)
+
+type memoryWorkOrderChangeLogRepository struct {
+	db *DB
+}
+
+func NewMemoryWorkOrderChangeLogRepository(db *DB) ports.WorkOrderChangeLogRepository {
+	return &memoryWorkOrderChangeLogRepository{db: db}
+}
+
+func (r *memoryWorkOrderChangeLogRepository) Create(ctx context.Context, changeLog domain.WorkOrderChangeLog) error {
+	if changeLog.ID == uuid.Nil {
+		changeLog.ID = uuid.New()
+	}
+	if changeLog.CreatedAt.IsZero() {
+		changeLog.CreatedAt = time.Now()
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	r.db.changeLogs = append(r.db.changeLogs, changeLog)
+	return nil
+}
+
+func (r *memoryWorkOrderChangeLogRepository) FindByWorkOrderID(ctx context.Context, workOrderID uuid.UUID) ([]domain.WorkOrderChangeLog, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	// appended in order, oldest first already
+	changeLogs := []domain.WorkOrderChangeLog{}
+	for _, changeLog := range r.db.changeLogs {
+		if changeLog.WorkOrderID == workOrderID {
+			changeLogs = append(changeLogs, changeLog)
+		}
+	}
+
+	return changeLogs, nil
+}
@@ -0,0 +1,240 @@
+// internal/adapters/memory/workorder_repository.go
+
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+)
+
+var (
+	ErrNoWID = domain.NewValidationError("work_order_id_required", "no se encontró ID asociada al work order")
+)
+
+type memoryWorkOrderRepository struct {
+	db *DB
+}
+
+func NewMemoryWorkOrderRepository(db *DB) ports.WorkOrderRepository {
+	return &memoryWorkOrderRepository{db: db}
+}
+
+func (r *memoryWorkOrderRepository) Create(ctx context.Context, workOrder domain.WorkOrder) error {
+	if workOrder.ID == uuid.Nil {
+		workOrder.ID = uuid.New()
+	}
+	// same defaults the table has
+	if workOrder.Status == "" {
+		workOrder.Status = domain.StatusNew
+	}
+	if workOrder.CreatedAt.IsZero() {
+		workOrder.CreatedAt = time.Now()
+	}
+	if workOrder.Version == 0 {
+		workOrder.Version = 1
+	}
+	// customer is preloaded on reads, never stored
+	workOrder.Customer = domain.Customer{}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	// same as the partial unique index uq_work_orders_open_customer_type and the exclusion
+	// constraint ex_work_orders_open_customer_window
+	if workOrder.Status == domain.StatusNew {
+		for _, stored := range r.db.workOrders {
+			if stored.Status != domain.StatusNew || stored.CustomerID != workOrder.CustomerID {
+				continue
+			}
+			if stored.Type == workOrder.Type {
+				return domain.ErrDuplicated
+			}
+			if overlaps(stored.PlannedDateBegin, stored.PlannedDateEnd, workOrder.PlannedDateBegin, workOrder.PlannedDateEnd) {
+				return domain.ErrScheduleOverlap
+			}
+		}
+	}
+
+	r.db.workOrders[workOrder.ID] = workOrder
+	return nil
+}
+
+func (r *memoryWorkOrderRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.WorkOrder, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	workOrder, ok := r.db.workOrders[id]
+	if !ok {
+		// same error as gorm repository
+		return nil, domain.ErrWorkOrderNotFound
+	}
+	// preload of customer, condition 9
+	r.db.preloadCustomer(&workOrder)
+	// founded
+	return &workOrder, nil
+}
+
+func (r *memoryWorkOrderRepository) FindByFilter(ctx context.Context, filters ports.WorkOrderFilters) (ports.Page[domain.WorkOrder], error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var workOrders []domain.WorkOrder
+	for _, workOrder := range r.db.workOrders {
+		// same conditions the gorm repository joins with where
+		if filters.Since != nil && workOrder.PlannedDateBegin.Before(*filters.Since) {
+			continue
+		}
+		if filters.Until != nil && workOrder.PlannedDateEnd.After(*filters.Until) {
+			continue
+		}
+		if filters.Status != nil && workOrder.Status != *filters.Status {
+			continue
+		}
+		if filters.CustomerID != nil && workOrder.CustomerID != *filters.CustomerID {
+			continue
+		}
+		if filters.Type != nil && workOrder.Type != *filters.Type {
+			continue
+		}
+		// like ILIKE
+		if filters.Search != "" && !strings.Contains(strings.ToLower(workOrder.Description), strings.ToLower(filters.Search)) {
+			continue
+		}
+		if filters.CreatedSince != nil && workOrder.CreatedAt.Before(*filters.CreatedSince) {
+			continue
+		}
+		if filters.CreatedUntil != nil && workOrder.CreatedAt.After(*filters.CreatedUntil) {
+			continue
+		}
+		r.db.preloadCustomer(&workOrder)
+		workOrders = append(workOrders, workOrder)
+	}
+
+	return paginate(workOrders, filters.Pagination, workOrderCursor), nil
+}
+
+func (r *memoryWorkOrderRepository) FindByCustomerID(ctx context.Context, customerID uuid.UUID, pagination ports.Pagination) (ports.Page[domain.WorkOrder], error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var workOrders []domain.WorkOrder
+	for _, workOrder := range r.db.workOrders {
+		if workOrder.CustomerID != customerID {
+			continue
+		}
+		r.db.preloadCustomer(&workOrder)
+		workOrders = append(workOrders, workOrder)
+	}
+
+	return paginate(workOrders, pagination, workOrderCursor), nil
+}
+
+func (r *memoryWorkOrderRepository) Update(ctx context.Context, workOrder domain.WorkOrder) error {
+	// if no id given error
+	if workOrder.ID == uuid.Nil {
+		return ErrNoWID
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	stored, ok := r.db.workOrders[workOrder.ID]
+	// same as the WHERE version = ? of the gorm repository
+	if !ok || stored.Version != workOrder.Version {
+		return domain.ErrVersionConflict
+	}
+	// exclusion constraint, a rescheduled order can not overlap another open one
+	if workOrder.Status == domain.StatusNew {
+		for _, other := range r.db.workOrders {
+			if other.ID == workOrder.ID || other.Status != domain.StatusNew || other.CustomerID != workOrder.CustomerID {
+				continue
+			}
+			if overlaps(other.PlannedDateBegin, other.PlannedDateEnd, workOrder.PlannedDateBegin, workOrder.PlannedDateEnd) {
+				return domain.ErrScheduleOverlap
+			}
+		}
+	}
+	workOrder.Version++
+	workOrder.CreatedAt = stored.CreatedAt
+	workOrder.Customer = domain.Customer{}
+	r.db.workOrders[workOrder.ID] = workOrder
+	return nil
+}
+
+func (r *memoryWorkOrderRepository) FindOverlapping(ctx context.Context, customerID uuid.UUID, begin, end time.Time, excludeID uuid.UUID) ([]domain.WorkOrder, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	workOrders := []domain.WorkOrder{}
+	for _, workOrder := range r.db.workOrders {
+		if workOrder.CustomerID != customerID || workOrder.Status != domain.StatusNew || workOrder.ID == excludeID {
+			continue
+		}
+		if overlaps(workOrder.PlannedDateBegin, workOrder.PlannedDateEnd, begin, end) {
+			workOrders = append(workOrders, workOrder)
+		}
+	}
+	sort.Slice(workOrders, func(i, j int) bool {
+		return workOrders[i].PlannedDateBegin.Before(workOrders[j].PlannedDateBegin)
+	})
+	return workOrders, nil
+}
+
+func (r *memoryWorkOrderRepository) FindConflicts(ctx context.Context, since, until time.Time, customerID *uuid.UUID) ([]ports.WorkOrderConflict, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	// open orders with part of their window inside the range
+	var open []domain.WorkOrder
+	for _, workOrder := range r.db.workOrders {
+		if workOrder.Status != domain.StatusNew || (customerID != nil && workOrder.CustomerID != *customerID) {
+			continue
+		}
+		if overlaps(workOrder.PlannedDateBegin, workOrder.PlannedDateEnd, since, until) {
+			r.db.preloadCustomer(&workOrder)
+			open = append(open, workOrder)
+		}
+	}
+	// same order as the gorm repository
+	sort.Slice(open, func(i, j int) bool {
+		if !open[i].PlannedDateBegin.Equal(open[j].PlannedDateBegin) {
+			return open[i].PlannedDateBegin.Before(open[j].PlannedDateBegin)
+		}
+		return open[i].ID.String() < open[j].ID.String()
+	})
+
+	conflicts := []ports.WorkOrderConflict{}
+	for _, a := range open {
+		for _, b := range open {
+			// a.id < b.id so a pair is not listed twice
+			if a.CustomerID != b.CustomerID || a.ID.String() >= b.ID.String() {
+				continue
+			}
+			if overlaps(a.PlannedDateBegin, a.PlannedDateEnd, b.PlannedDateBegin, b.PlannedDateEnd) {
+				conflicts = append(conflicts, ports.WorkOrderConflict{WorkOrder: a, ConflictsWith: b})
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+// same as && between two tstzrange, the end is not part of the window
+func overlaps(aBegin, aEnd, bBegin, bEnd time.Time) bool {
+	return aBegin.Before(bEnd) && bBegin.Before(aEnd)
+}
+
+// fills workOrder.Customer like Preload("Customer"), callers must hold the lock
+func (db *DB) preloadCustomer(workOrder *domain.WorkOrder) {
+	customer := db.customers[workOrder.CustomerID]
+	// gorm skips soft deleted rows when preloading too
+	if customer.DeletedAt.Valid {
+		customer = domain.Customer{}
+	}
+	workOrder.Customer = customer
+}
@@ -0,0 +1,54 @@
+// internal/adapters/memory/workorder_status_change_repository.go
+
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+)
+
+type memoryWorkOrderStatusChangeRepository struct {
+	db *DB
+}
+
+func NewMemoryWorkOrderStatusChangeRepository(db *DB) ports.WorkOrderStatusChangeRepository {
+	return &memoryWorkOrderStatusChangeRepository{db: db}
+}
+
+func (r *memoryWorkOrderStatusChangeRepository) Create(ctx context.Context, change domain.WorkOrderStatusChange) error {
+	if change.ID == uuid.Nil {
+		change.ID = uuid.New()
+	}
+	if change.CreatedAt.IsZero() {
+		change.CreatedAt = time.Now()
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	// same as the foreign key of the table
+	if _, ok := r.db.workOrders[change.WorkOrderID]; !ok {
+		return domain.ErrWorkOrderNotFound
+	}
+	r.db.statusChanges = append(r.db.statusChanges, change)
+	return nil
+}
+
+func (r *memoryWorkOrderStatusChangeRepository) FindByWorkOrderID(ctx context.Context, workOrderID uuid.UUID) ([]domain.WorkOrderStatusChange, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	// appended in order, oldest first already
+	changes := []domain.WorkOrderStatusChange{}
+	for _, change := range r.db.statusChanges {
+		if change.WorkOrderID == workOrderID {
+			changes = append(changes, change)
+		}
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,136 @@
+// internal/adapters/repotest/idempotency.go
+
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+)
+
+// RunIdempotencyStore runs the suite of the Idempotency-Key stores on the one newStore returns.
+// Every test uses its own keys, the store can be shared
+func RunIdempotencyStore(t *testing.T, newStore func(t *testing.T) ports.IdempotencyStore) {
+	t.Run("CompleteHeldKey", func(t *testing.T) { testCompleteHeldKey(t, newStore(t)) })
+	t.Run("TakenOverKey", func(t *testing.T) { testTakenOverKey(t, newStore(t)) })
+}
+
+// reserves a new key locked for lock, fails when it is not reserved
+func mustReserve(t *testing.T, store ports.IdempotencyStore, key string, lock time.Duration) domain.IdempotencyKey {
+	t.Helper()
+	now := time.Now()
+	stored, reserved, err := store.Reserve(context.Background(), domain.IdempotencyKey{
+		Key:         key,
+		Fingerprint: "POST /api/v1/customers",
+		LockedUntil: now.Add(lock),
+		ExpiresAt:   now.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("reserving %s: %v", key, err)
+	}
+	if !reserved {
+		t.Fatalf("%s not reserved, stored %+v", key, stored)
+	}
+	return *stored
+}
+
+// completes reserved with a 201 and checks the result
+func complete(t *testing.T, store ports.IdempotencyStore, reserved domain.IdempotencyKey, want bool) {
+	t.Helper()
+	completed, err := store.Complete(context.Background(), reserved, 201, "application/json", map[string]string{"ETag": `"1"`}, []byte(`{"id":1}`))
+	if err != nil {
+		t.Fatalf("completing: %v", err)
+	}
+	if completed != want {
+		t.Fatalf("completed %t, want %t", completed, want)
+	}
+}
+
+// what a retry with the same key gets, nil when it would run the request again
+func retry(t *testing.T, store ports.IdempotencyStore, key string) *domain.IdempotencyKey {
+	t.Helper()
+	now := time.Now()
+	stored, reserved, err := store.Reserve(context.Background(), domain.IdempotencyKey{
+		Key:         key,
+		Fingerprint: "POST /api/v1/customers",
+		LockedUntil: now.Add(time.Minute),
+		ExpiresAt:   now.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("retrying %s: %v", key, err)
+	}
+	if reserved {
+		return nil
+	}
+	return stored
+}
+
+// the request that reserved the key completes it once, also after its lock expired when no
+// retry took it
+func testCompleteHeldKey(t *testing.T, store ports.IdempotencyStore) {
+	tests := []struct {
+		name string
+		lock time.Duration
+	}{
+		{"locked", time.Minute},
+		{"lock expired", -time.Second},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			key := uuid.NewString()
+			reserved := mustReserve(t, store, key, tc.lock)
+			complete(t, store, reserved, true)
+			// only once
+			complete(t, store, reserved, false)
+
+			stored := retry(t, store, key)
+			if stored == nil || stored.CompletedAt == nil || stored.StatusCode != 201 || string(stored.Body) != `{"id":1}` || stored.Headers["ETag"] != `"1"` {
+				t.Fatalf("retry got %+v, want the stored response", stored)
+			}
+			// a completed key is not released by a late failure
+			if err := store.Release(context.Background(), reserved); err != nil {
+				t.Fatal(err)
+			}
+			if stored := retry(t, store, key); stored == nil || stored.CompletedAt == nil {
+				t.Fatalf("retry got %+v after the release, want the stored response", stored)
+			}
+		})
+	}
+
+	t.Run("released", func(t *testing.T) {
+		key := uuid.NewString()
+		reserved := mustReserve(t, store, key, time.Minute)
+		if err := store.Release(context.Background(), reserved); err != nil {
+			t.Fatal(err)
+		}
+		complete(t, store, reserved, false)
+		if stored := retry(t, store, key); stored != nil {
+			t.Fatalf("retry got %+v, want the key free", stored)
+		}
+	})
+}
+
+// once a retry takes over an expired lock the first request can neither complete nor release
+// the key
+func testTakenOverKey(t *testing.T, store ports.IdempotencyStore) {
+	key := uuid.NewString()
+	first := mustReserve(t, store, key, -time.Second)
+	second := mustReserve(t, store, key, time.Minute)
+
+	complete(t, store, first, false)
+	if err := store.Release(context.Background(), first); err != nil {
+		t.Fatal(err)
+	}
+	if stored := retry(t, store, key); stored == nil || stored.CompletedAt != nil {
+		t.Fatalf("retry got %+v, want the second request in progress", stored)
+	}
+
+	complete(t, store, second, true)
+	if stored := retry(t, store, key); stored == nil || stored.CompletedAt == nil {
+		t.Fatalf("retry got %+v, want the response of the second request", stored)
+	}
+}
@@ -0,0 +1,269 @@
+// internal/adapters/repotest/repotest.go
+
+// Package repotest has the behaviour every implementation of the repositories must have, the
+// tests of storage and memory run it so postgres, sqlite and memory answer the same
+package repotest
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+)
+
+// Repositories are the ones under test, all on the same database
+type Repositories struct {
+	Customers  ports.CustomerRepository
+	WorkOrders ports.WorkOrderRepository
+}
+
+// Run runs the suite on the repositories newRepos returns. Every test works on its own
+// customers, so the database can be shared and does not need to be empty
+func Run(t *testing.T, newRepos func(t *testing.T) Repositories) {
+	t.Run("UniqueOpenOrder", func(t *testing.T) { testUniqueOpenOrder(t, newRepos(t)) })
+	t.Run("OverlapExclusion", func(t *testing.T) { testOverlapExclusion(t, newRepos(t)) })
+	t.Run("VersionConflict", func(t *testing.T) { testVersionConflict(t, newRepos(t)) })
+	t.Run("Pagination", func(t *testing.T) { testPagination(t, newRepos(t)) })
+}
+
+// one open order of each type per customer, done or cancelled ones do not count
+func testUniqueOpenOrder(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	customer := createCustomer(t, repos)
+	begin := plannedBase()
+
+	first := newWorkOrder(customer.ID, domain.TypeActivate, begin)
+	mustCreate(t, repos, first)
+
+	// another window so only the unique index can reject it
+	second := newWorkOrder(customer.ID, domain.TypeActivate, begin.Add(24*time.Hour))
+	if err := repos.WorkOrders.Create(ctx, second); !errors.Is(err, domain.ErrDuplicated) {
+		t.Fatalf("second open order of the same type: got %v, want %v", err, domain.ErrDuplicated)
+	}
+
+	// the other type is allowed
+	mustCreate(t, repos, newWorkOrder(customer.ID, domain.TypeCancell, begin.Add(48*time.Hour)))
+
+	// once the first is done a new one can be opened
+	stored := mustFind(t, repos, first.ID)
+	stored.Status = domain.StatusDone
+	if err := repos.WorkOrders.Update(ctx, *stored); err != nil {
+		t.Fatalf("completing the first order: %v", err)
+	}
+	if err := repos.WorkOrders.Create(ctx, second); err != nil {
+		t.Fatalf("open order after the first was done: %v", err)
+	}
+}
+
+// open orders of a customer can not share time, adjacent windows can
+func testOverlapExclusion(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	customer := createCustomer(t, repos)
+	begin := plannedBase()
+
+	first := newWorkOrder(customer.ID, domain.TypeActivate, begin)
+	mustCreate(t, repos, first)
+
+	overlapping := newWorkOrder(customer.ID, domain.TypeCancell, begin.Add(30*time.Minute))
+	if err := repos.WorkOrders.Create(ctx, overlapping); !errors.Is(err, domain.ErrScheduleOverlap) {
+		t.Fatalf("create inside the window of another open order: got %v, want %v", err, domain.ErrScheduleOverlap)
+	}
+
+	// [begin, end) ranges, starting when the first ends is not an overlap
+	adjacent := newWorkOrder(customer.ID, domain.TypeCancell, first.PlannedDateEnd)
+	mustCreate(t, repos, adjacent)
+
+	// rescheduling into the first window is rejected too
+	stored := mustFind(t, repos, adjacent.ID)
+	stored.PlannedDateBegin = begin.Add(30 * time.Minute)
+	stored.PlannedDateEnd = begin.Add(90 * time.Minute)
+	if err := repos.WorkOrders.Update(ctx, *stored); !errors.Is(err, domain.ErrScheduleOverlap) {
+		t.Fatalf("reschedule inside the window of another open order: got %v, want %v", err, domain.ErrScheduleOverlap)
+	}
+
+	// cancelled orders free their window
+	cancelled := mustFind(t, repos, first.ID)
+	cancelled.Status = domain.StatusCancelled
+	if err := repos.WorkOrders.Update(ctx, *cancelled); err != nil {
+		t.Fatalf("cancelling the first order: %v", err)
+	}
+	if err := repos.WorkOrders.Update(ctx, *stored); err != nil {
+		t.Fatalf("reschedule after the other order was cancelled: %v", err)
+	}
+}
+
+// an update or delete with a version that is not the stored one changes nothing
+func testVersionConflict(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	customer := createCustomer(t, repos)
+
+	read := mustFindCustomer(t, repos, customer.ID)
+	stale := *read
+
+	read.Address = "calle 1"
+	if err := repos.Customers.Update(ctx, *read); err != nil {
+		t.Fatalf("first customer update: %v", err)
+	}
+	stale.Address = "calle 2"
+	if err := repos.Customers.Update(ctx, stale); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Fatalf("customer update with a stale version: got %v, want %v", err, domain.ErrVersionConflict)
+	}
+	stored := mustFindCustomer(t, repos, customer.ID)
+	if stored.Version != stale.Version+1 || stored.Address != "calle 1" {
+		t.Fatalf("customer after the conflict: version %d address %q, want version %d address %q", stored.Version, stored.Address, stale.Version+1, "calle 1")
+	}
+
+	workOrder := newWorkOrder(customer.ID, domain.TypeActivate, plannedBase())
+	mustCreate(t, repos, workOrder)
+	readOrder := mustFind(t, repos, workOrder.ID)
+	staleOrder := *readOrder
+
+	readOrder.Description = "primera"
+	if err := repos.WorkOrders.Update(ctx, *readOrder); err != nil {
+		t.Fatalf("first work order update: %v", err)
+	}
+	staleOrder.Description = "segunda"
+	if err := repos.WorkOrders.Update(ctx, staleOrder); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Fatalf("work order update with a stale version: got %v, want %v", err, domain.ErrVersionConflict)
+	}
+	storedOrder := mustFind(t, repos, workOrder.ID)
+	if storedOrder.Version != staleOrder.Version+1 || storedOrder.Description != "primera" {
+		t.Fatalf("work order after the conflict: version %d description %q, want version %d description %q", storedOrder.Version, storedOrder.Description, staleOrder.Version+1, "primera")
+	}
+
+	// a delete with the stale version does not hide the customer, with the stored one it does
+	if err := repos.Customers.Delete(ctx, customer.ID, stale.Version); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Fatalf("customer delete with a stale version: got %v, want %v", err, domain.ErrVersionConflict)
+	}
+	mustFindCustomer(t, repos, customer.ID)
+	if err := repos.Customers.Delete(ctx, customer.ID, stored.Version); err != nil {
+		t.Fatalf("customer delete with the stored version: %v", err)
+	}
+	if _, err := repos.Customers.FindByID(ctx, customer.ID); !errors.Is(err, domain.ErrCustomerNotFound) {
+		t.Fatalf("customer after the delete: got %v, want %v", err, domain.ErrCustomerNotFound)
+	}
+}
+
+// keyset pages on (created_at, id) in both directions, the id breaks ties of created_at
+func testPagination(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	customer := createCustomer(t, repos)
+	created := time.Now().UTC().Truncate(time.Second).Add(-time.Hour)
+
+	var want []domain.WorkOrder
+	for i, offset := range []time.Duration{0, time.Minute, time.Minute, 2 * time.Minute, 3 * time.Minute} {
+		// done orders, the open order rules do not get in the way
+		workOrder := newWorkOrder(customer.ID, domain.TypeActivate, plannedBase().Add(time.Duration(i)*24*time.Hour))
+		workOrder.Status = domain.StatusDone
+		workOrder.CreatedAt = created.Add(offset)
+		mustCreate(t, repos, workOrder)
+		want = append(want, workOrder)
+	}
+	sort.Slice(want, func(i, j int) bool {
+		if want[i].CreatedAt.Equal(want[j].CreatedAt) {
+			return want[i].ID.String() < want[j].ID.String()
+		}
+		return want[i].CreatedAt.Before(want[j].CreatedAt)
+	})
+
+	for _, order := range []ports.SortOrder{ports.SortAsc, ports.SortDesc} {
+		expected := make([]uuid.UUID, len(want))
+		for i, workOrder := range want {
+			expected[i] = workOrder.ID
+		}
+		if order == ports.SortDesc {
+			for i, j := 0, len(expected)-1; i < j; i, j = i+1, j-1 {
+				expected[i], expected[j] = expected[j], expected[i]
+			}
+		}
+
+		var got []uuid.UUID
+		pagination := ports.Pagination{Limit: 2, Sort: order}
+		for pages := 0; ; pages++ {
+			if pages > len(want) {
+				t.Fatalf("%s: the cursor never reaches the last page", order)
+			}
+			page, err := repos.WorkOrders.FindByCustomerID(ctx, customer.ID, pagination)
+			if err != nil {
+				t.Fatalf("%s: %v", order, err)
+			}
+			if page.Total != int64(len(want)) {
+				t.Fatalf("%s: total %d, want %d", order, page.Total, len(want))
+			}
+			for _, workOrder := range page.Items {
+				got = append(got, workOrder.ID)
+			}
+			if page.NextCursor == nil {
+				break
+			}
+			pagination.Cursor = page.NextCursor
+		}
+
+		if len(got) != len(expected) {
+			t.Fatalf("%s: got %d orders, want %d", order, len(got), len(expected))
+		}
+		for i := range expected {
+			if got[i] != expected[i] {
+				t.Fatalf("%s: order %d is %s, want %s", order, i, got[i], expected[i])
+			}
+		}
+	}
+}
+
+// a week from now on the hour, far from the working hours and lead time of any policy
+func plannedBase() time.Time {
+	return time.Now().UTC().Truncate(time.Hour).Add(7 * 24 * time.Hour)
+}
+
+func createCustomer(t *testing.T, repos Repositories) domain.Customer {
+	t.Helper()
+	customer := domain.Customer{ID: uuid.New(), FirstName: "Ana", LastName: "Pérez", Address: "calle 0", Version: 1}
+	if err := repos.Customers.Create(context.Background(), customer); err != nil {
+		t.Fatalf("creating customer: %v", err)
+	}
+	return customer
+}
+
+// open order of one hour starting at begin
+func newWorkOrder(customerID uuid.UUID, woType domain.Type, begin time.Time) domain.WorkOrder {
+	return domain.WorkOrder{
+		ID:               uuid.New(),
+		CustomerID:       customerID,
+		Description:      "orden de prueba",
+		PlannedDateBegin: begin,
+		PlannedDateEnd:   begin.Add(time.Hour),
+		Status:           domain.StatusNew,
+		Type:             woType,
+		Version:          1,
+	}
+}
+
+func mustCreate(t *testing.T, repos Repositories, workOrder domain.WorkOrder) {
+	t.Helper()
+	if err := repos.WorkOrders.Create(context.Background(), workOrder); err != nil {
+		t.Fatalf("creating work order: %v", err)
+	}
+}
+
+func mustFind(t *testing.T, repos Repositories, id uuid.UUID) *domain.WorkOrder {
+	t.Helper()
+	workOrder, err := repos.WorkOrders.FindByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("finding work order %s: %v", id, err)
+	}
+	return workOrder
+}
+
+func mustFindCustomer(t *testing.T, repos Repositories, id uuid.UUID) *domain.Customer {
+	t.Helper()
+	customer, err := repos.Customers.FindByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("finding customer %s: %v", id, err)
+	}
+	return customer
+}
@@ -0,0 +1,26 @@
+// internal/adapters/rest/actor.go
+
+package rest
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// header with the user making the request, there is no auth yet so the front-end sends it
+const ActorHeader = "X-Actor"
+
+// anonymous is used when the header is missing
+const anonymousActor = "anonymous"
+
+// who is doing the request, used for audit logs. Copied because the header points to a buffer
+// fiber reuses in the next request and the memory repositories keep the string
+func actorFrom(c *fiber.Ctx) string {
+	actor := strings.TrimSpace(utils.CopyString(c.Get(ActorHeader)))
+	if actor == "" {
+		return anonymousActor
+	}
+	return actor
+}
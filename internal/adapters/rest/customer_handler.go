@@ -6,6 +6,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
 	"github.com/krud3/prueba-tecnica/internal/core/services"
 )
 
@@ -24,29 +25,39 @@ func NewCustomerHandler(cS *services.CustomerService) *CustomerHandler {
 // @Tags         customers
 // @Accept       json
 // @Produce      json
+// @Param        Idempotency-Key header string false "Llave única de la operación, los reintentos con la misma llave reciben la primera respuesta"
 // @Param        customer body CreateCustomerRequest true "Datos del Cliente a crear"
 // @Success      201 {object} domain.Customer
-// @Failure      400 {object} map[string]string "Error: Petición inválida"
-// @Failure      500 {object} map[string]string "Error: Error interno del servidor"
+// @Header       201 {string} ETag "Versión del cliente, usar en If-Match"
+// @Header       201 {string} Location "Ruta del cliente creado"
+// @Failure      400 {object} ProblemDetails "Error: Petición inválida"
+// @Failure      409 {object} ProblemDetails "Error: La petición con el mismo Idempotency-Key sigue en curso"
+// @Failure      422 {object} ProblemDetails "Error: Idempotency-Key usado con otra petición"
+// @Failure      500 {object} ProblemDetails "Error: Error interno del servidor"
 // @Router       /customers [post]
 func (cH *CustomerHandler) Create(c *fiber.Ctx) error {
 	// using dto now
 	var req CreateCustomerRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cuerpo de la petición inválido"})
+	// 400 with the list of invalid fields
+	if err := parseBody(c, &req); err != nil {
+		return err
 	}
 	// map DTO to domain.Customer
+	// id made here so the response has it
 	customer := domain.Customer{
+		ID:        uuid.New(),
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
 		Address:   req.Address,
 	}
 	// try to create
-	err := cH.cS.Create(c.Context(), customer)
+	err := cH.cS.Create(c.Context(), &customer)
 	if err != nil {
-		// 500 server error
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		// ErrorHandler answers with the right status
+		return err
 	}
+	setETag(c, customer.Version)
+	setLocation(c, customer.ID)
 	// 201 created
 	return c.Status(fiber.StatusCreated).JSON(customer)
 }
@@ -58,66 +69,201 @@ func (cH *CustomerHandler) Create(c *fiber.Ctx) error {
 // @Produce      json
 // @Param        id path string true "ID del Cliente (UUID)"
 // @Success      200 {object} domain.Customer
-// @Failure      400 {object} map[string]string "Error: ID inválido"
-// @Failure      404 {object} map[string]string "Error: Cliente no encontrado"
-// @Failure      500 {object} map[string]string "Error: Error interno del servidor"
+// @Header       200 {string} ETag "Versión del cliente, usar en If-Match"
+// @Failure      400 {object} ProblemDetails "Error: ID inválido"
+// @Failure      404 {object} ProblemDetails "Error: Cliente no encontrado"
+// @Failure      500 {object} ProblemDetails "Error: Error interno del servidor"
 // @Router       /customers/{id} [get]
 func (cH *CustomerHandler) GetByID(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 	customerID, err := uuid.Parse(idStr)
 	if err != nil {
 		// err ID
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El campo ID de la orden es inválido"})
+		return ErrInvalidID
 	}
-	// handler to get service to find by id
+	// handler to get service to find by id, 404 if not found
 	customer, err := cH.cS.FindByID(c.Context(), customerID)
 	if err != nil {
-		// 500 server error
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
-	}
-	if customer == nil {
-		// 404 not found
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "orden no encontrada"})
+		return err
 	}
+	setETag(c, customer.Version)
 	// 200 ok
 	return c.Status(fiber.StatusOK).JSON(customer)
 }
 
+// GetServicePeriods lista los periodos en que el cliente estuvo activo.
+// @Summary      Historial de servicio de un cliente
+// @Description  Devuelve los periodos de servicio del cliente del más antiguo al más reciente, cada uno abierto por una orden de activación y cerrado por una de cancelación. EndedAt es null en el periodo actual. active_days suma los días completos de todos los periodos, el actual cuenta hasta ahora.
+// @Tags         customers
+// @Produce      json
+// @Param        id path string true "ID del Cliente (UUID)"
+// @Success      200 {object} CustomerServicePeriodsResponse
+// @Failure      400 {object} ProblemDetails "Error: ID inválido"
+// @Failure      404 {object} ProblemDetails "Error: Cliente no encontrado"
+// @Failure      500 {object} ProblemDetails "Error: Error interno del servidor"
+// @Router       /customers/{id}/service-periods [get]
+func (cH *CustomerHandler) GetServicePeriods(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	customerID, err := uuid.Parse(idStr)
+	if err != nil {
+		// err ID
+		return ErrInvalidID
+	}
+	// 404 if not found
+	history, err := cH.cS.ServiceHistory(c.Context(), customerID)
+	if err != nil {
+		return err
+	}
+	// 200 ok
+	return c.Status(fiber.StatusOK).JSON(CustomerServicePeriodsResponse{
+		Data:       history.Periods,
+		Total:      len(history.Periods),
+		ActiveDays: history.ActiveDays,
+	})
+}
+
 // GetActive obtiene todos los clientes activos.
 // @Summary      Obtiene clientes activos
-// @Description  Devuelve una lista de todos los clientes cuyo estado es 'is_active = true'.
+// @Description  Devuelve una página de los clientes cuyo estado es 'is_active = true'. Usar next_cursor como cursor para pedir la siguiente página.
 // @Tags         customers
 // @Produce      json
-// @Success      200 {array} domain.Customer
-// @Failure      500 {object} map[string]string "Error: Error interno del servidor"
+// @Param        limit  query int    false "Tamaño de la página (1-200, por defecto 50)"
+// @Param        cursor query string false "Cursor devuelto en next_cursor por la página anterior"
+// @Param        sort   query string false "Orden por fecha de creación, el único soportado. Otro valor responde 400" Enums(created_at, -created_at)
+// @Success      200 {object} PageResponse[domain.Customer]
+// @Failure      400 {object} ProblemDetails "Error: Parámetro de paginación inválido"
+// @Failure      500 {object} ProblemDetails "Error: Error interno del servidor"
 // @Router       /customers/active [get]
 func (cH *CustomerHandler) GetActive(c *fiber.Ctx) error {
+	pagination, err := parsePagination(c)
+	if err != nil {
+		// 400
+		return err
+	}
 	// using handler to get the service to get actives
-	customers, err := cH.cS.GetActive(c.Context())
+	page, err := cH.cS.GetActive(c.Context(), pagination)
 	if err != nil {
 		// 500 server error due user can not send invalid data
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "error al buscar los clientes activos"})
+		return err
 	}
 	// 200 ok or empty
-	return c.Status(fiber.StatusOK).JSON(customers)
+	return c.Status(fiber.StatusOK).JSON(toPageResponse(page))
 }
 
 // GetAll obtiene todos los clientes.
 // @Summary      Obtiene todos los clientes
-// @Description  Devuelve una lista de todos los clientes.
+// @Description  Devuelve una página de todos los clientes. Usar next_cursor como cursor para pedir la siguiente página.
 // @Tags         customers
 // @Produce      json
-// @Success      200 {array} domain.Customer
-// @Failure      500 {object} map[string]string "Error: Error interno del servidor"
+// @Param        limit  query int    false "Tamaño de la página (1-200, por defecto 50)"
+// @Param        cursor query string false "Cursor devuelto en next_cursor por la página anterior"
+// @Param        sort   query string false "Orden por fecha de creación, el único soportado. Otro valor responde 400" Enums(created_at, -created_at)
+// @Success      200 {object} PageResponse[domain.Customer]
+// @Failure      400 {object} ProblemDetails "Error: Parámetro de paginación inválido"
+// @Failure      500 {object} ProblemDetails "Error: Error interno del servidor"
 // @Router       /customers/all [get]
 func (cH *CustomerHandler) GetAll(c *fiber.Ctx) error {
+	pagination, err := parsePagination(c)
+	if err != nil {
+		// 400
+		return err
+	}
 
-	customers, err := cH.cS.GetAll(c.Context())
+	page, err := cH.cS.GetAll(c.Context(), pagination)
 
 	if err != nil {
 		// 500 server error due user can not send invalid data
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "error al buscar todos los clientes"})
+		return err
 	}
 	// 200 ok or empty
-	return c.Status(fiber.StatusOK).JSON(customers)
+	return c.Status(fiber.StatusOK).JSON(toPageResponse(page))
+}
+
+// Update actualiza los datos de un cliente.
+// @Summary      Actualiza un cliente
+// @Description  Cambia nombres y/o dirección de un cliente. Solo se modifican los campos enviados, el estado (activo, fechas) solo cambia con órdenes de trabajo.
+// @Tags         customers
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "ID del Cliente (UUID)"
+// @Param        If-Match header string false "ETag del cliente leído antes, si cambió se responde 412"
+// @Param        customer body UpdateCustomerRequest true "Campos del Cliente a modificar"
+// @Success      200 {object} domain.Customer
+// @Header       200 {string} ETag "Nueva versión del cliente"
+// @Failure      400 {object} ProblemDetails "Error: Petición inválida"
+// @Failure      404 {object} ProblemDetails "Error: Cliente no encontrado"
+// @Failure      409 {object} ProblemDetails "Error: El cliente cambió mientras se actualizaba"
+// @Failure      412 {object} ProblemDetails "Error: If-Match no coincide con la versión actual"
+// @Failure      500 {object} ProblemDetails "Error: Error interno del servidor"
+// @Router       /customers/{id} [patch]
+func (cH *CustomerHandler) Update(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	customerID, err := uuid.Parse(idStr)
+	if err != nil {
+		// err ID
+		return ErrInvalidID
+	}
+
+	// nil when the client does not send If-Match
+	version, err := ifMatch(c)
+	if err != nil {
+		return err
+	}
+
+	var req UpdateCustomerRequest
+	// 400 with the list of invalid fields
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+	// map DTO to changes, state fields can not arrive here
+	changes := ports.CustomerChanges{
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Address:   req.Address,
+	}
+
+	// 400 empty values, 404 not found, 412 stale version
+	customer, err := cH.cS.UpdateDetails(c.Context(), customerID, changes, version)
+	if err != nil {
+		return err
+	}
+	setETag(c, customer.Version)
+	// 200 ok
+	return c.Status(fiber.StatusOK).JSON(customer)
+}
+
+// Delete elimina un cliente.
+// @Summary      Elimina un cliente
+// @Description  Borrado lógico del cliente, deja de aparecer en las consultas pero sus órdenes de trabajo se conservan. No se puede eliminar mientras tenga órdenes abiertas (estado 'new').
+// @Tags         customers
+// @Produce      json
+// @Param        id path string true "ID del Cliente (UUID)"
+// @Param        If-Match header string false "ETag del cliente leído antes, si cambió se responde 412"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} ProblemDetails "Error: ID inválido"
+// @Failure      404 {object} ProblemDetails "Error: Cliente no encontrado"
+// @Failure      409 {object} ProblemDetails "Error: El cliente tiene órdenes abiertas o cambió mientras se eliminaba"
+// @Failure      412 {object} ProblemDetails "Error: If-Match no coincide con la versión actual"
+// @Failure      500 {object} ProblemDetails "Error: Error interno del servidor"
+// @Router       /customers/{id} [delete]
+func (cH *CustomerHandler) Delete(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	customerID, err := uuid.Parse(idStr)
+	if err != nil {
+		// err ID
+		return ErrInvalidID
+	}
+
+	version, err := ifMatch(c)
+	if err != nil {
+		return err
+	}
+
+	// 404 not found, 412 stale version, 409 changed after the check
+	err = cH.cS.Delete(c.Context(), customerID, version)
+	if err != nil {
+		return err
+	}
+	// 200 ok
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Cliente eliminado exitosamente"})
 }
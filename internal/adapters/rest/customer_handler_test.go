@@ -0,0 +1,145 @@
+// internal/adapters/rest/customer_handler_test.go
+
+package rest
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func TestGetServicePeriods(t *testing.T) {
+	env := newHandlerEnv()
+	never := env.createCustomer(t)
+	active := env.createCustomer(t)
+	workOrder := env.createOrder(t, active.ID, "ana")
+	if status, data := env.request(t, fiber.MethodPatch, "/work-orders/"+workOrder.ID.String()+"/complete", "ana", ""); status != fiber.StatusOK {
+		t.Fatalf("completing: status %d %s", status, data)
+	}
+
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+		// for each period, true when it is still open
+		wantOpen []bool
+	}{
+		{"never active", never.ID.String(), fiber.StatusOK, []bool{}},
+		{"activated", active.ID.String(), fiber.StatusOK, []bool{true}},
+		{"unknown customer", uuid.NewString(), fiber.StatusNotFound, nil},
+		{"invalid id", "123", fiber.StatusBadRequest, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, data := env.request(t, fiber.MethodGet, "/customers/"+tc.id+"/service-periods", "", "")
+			if status != tc.wantStatus {
+				t.Fatalf("status %d, want %d: %s", status, tc.wantStatus, data)
+			}
+			if tc.wantStatus != fiber.StatusOK {
+				return
+			}
+
+			var resp CustomerServicePeriodsResponse
+			decode(t, data, &resp)
+			// data is a list even without periods
+			if resp.Data == nil || len(resp.Data) != len(tc.wantOpen) || resp.Total != len(resp.Data) || resp.ActiveDays != 0 {
+				t.Fatalf("response %s, want %d periods and 0 active days", data, len(tc.wantOpen))
+			}
+			for i, period := range resp.Data {
+				if open := period.EndedAt == nil; open != tc.wantOpen[i] || period.StartWorkOrderID == nil || *period.StartWorkOrderID != workOrder.ID {
+					t.Fatalf("period %d open %t started by %v, want open %t by %s", i, open, period.StartWorkOrderID, tc.wantOpen[i], workOrder.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateCustomer(t *testing.T) {
+	tests := []struct {
+		name    string
+		unknown bool
+		ifMatch string
+		body    string
+		// status and code of the problem, only for errors
+		wantStatus int
+		wantCode   string
+		wantETag   string
+	}{
+		{name: "address", body: `{"address":"calle 2"}`, wantStatus: fiber.StatusOK, wantETag: `"2"`},
+		{name: "current If-Match", ifMatch: `"1"`, body: `{"firstName":"Luis"}`, wantStatus: fiber.StatusOK, wantETag: `"2"`},
+		{name: "empty first name", body: `{"firstName":""}`, wantStatus: fiber.StatusBadRequest, wantCode: "invalid_fields"},
+		{name: "blank address", body: `{"address":"  "}`, wantStatus: fiber.StatusBadRequest, wantCode: "invalid_fields"},
+		{name: "malformed json", body: `{"address":`, wantStatus: fiber.StatusBadRequest, wantCode: ErrInvalidBody.Code},
+		{name: "unknown customer", unknown: true, body: `{"address":"calle 2"}`, wantStatus: fiber.StatusNotFound, wantCode: "customer_not_found"},
+		{name: "stale If-Match", ifMatch: `"7"`, body: `{"address":"calle 2"}`, wantStatus: fiber.StatusPreconditionFailed, wantCode: "version_mismatch"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			env := newHandlerEnv()
+			customer := env.createCustomer(t)
+			id := customer.ID.String()
+			if tc.unknown {
+				id = uuid.NewString()
+			}
+			headers := map[string]string{}
+			if tc.ifMatch != "" {
+				headers[fiber.HeaderIfMatch] = tc.ifMatch
+			}
+
+			resp, data := env.do(t, fiber.MethodPatch, "/customers/"+id, headers, tc.body)
+			checkProblem(t, resp.StatusCode, data, tc.wantStatus, tc.wantCode)
+			if tc.wantStatus == fiber.StatusOK && resp.Header.Get(fiber.HeaderETag) != tc.wantETag {
+				t.Fatalf("ETag %q, want %q", resp.Header.Get(fiber.HeaderETag), tc.wantETag)
+			}
+		})
+	}
+}
+
+func TestDeleteCustomer(t *testing.T) {
+	tests := []struct {
+		name    string
+		unknown bool
+		// the customer has an open activation order
+		openOrder  bool
+		ifMatch    string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "without orders", wantStatus: fiber.StatusOK},
+		{name: "current If-Match", ifMatch: `"1"`, wantStatus: fiber.StatusOK},
+		{name: "with an open order", openOrder: true, wantStatus: fiber.StatusConflict, wantCode: "customer_has_open_work_orders"},
+		{name: "unknown customer", unknown: true, wantStatus: fiber.StatusNotFound, wantCode: "customer_not_found"},
+		{name: "stale If-Match", ifMatch: `"7"`, wantStatus: fiber.StatusPreconditionFailed, wantCode: "version_mismatch"},
+		{name: "invalid If-Match", ifMatch: "siete", wantStatus: fiber.StatusBadRequest},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			env := newHandlerEnv()
+			customer := env.createCustomer(t)
+			if tc.openOrder {
+				env.createOrder(t, customer.ID, "ana")
+			}
+			id := customer.ID.String()
+			if tc.unknown {
+				id = uuid.NewString()
+			}
+			headers := map[string]string{}
+			if tc.ifMatch != "" {
+				headers[fiber.HeaderIfMatch] = tc.ifMatch
+			}
+
+			resp, data := env.do(t, fiber.MethodDelete, "/customers/"+id, headers, "")
+			checkProblem(t, resp.StatusCode, data, tc.wantStatus, tc.wantCode)
+
+			// deleted customers are gone from the api, the others stay
+			wantFound := fiber.StatusOK
+			if tc.wantStatus == fiber.StatusOK {
+				wantFound = fiber.StatusNotFound
+			}
+			if status, _ := env.request(t, fiber.MethodGet, "/customers/"+customer.ID.String(), "", ""); status != wantFound {
+				t.Fatalf("GET after the delete: status %d, want %d", status, wantFound)
+			}
+		})
+	}
+}
@@ -9,16 +9,65 @@ import (
 	"github.com/krud3/prueba-tecnica/internal/core/domain"
 )
 
+// validate tags are checked by parseBody, see validation.go
 type CreateCustomerRequest struct {
-	FirstName string `json:"firstName"`
-	LastName  string `json:"lastName"`
-	Address   string `json:"address"`
+	FirstName string `json:"firstName" validate:"notblank,max=255"`
+	LastName  string `json:"lastName" validate:"notblank,max=255"`
+	Address   string `json:"address" validate:"notblank"`
+}
+
+// partial update, fields not sent stay the same. State fields are not here on purpose
+type UpdateCustomerRequest struct {
+	FirstName *string `json:"firstName" validate:"omitempty,notblank,max=255"`
+	LastName  *string `json:"lastName" validate:"omitempty,notblank,max=255"`
+	Address   *string `json:"address" validate:"omitempty,notblank"`
 }
 
 type CreateWorkOrderRequest struct {
-	CustomerID       uuid.UUID   `json:"customerID"`
-	Description      string      `json:"description"`
-	PlannedDateBegin time.Time   `json:"plannedDateBegin"`
-	PlannedDateEnd   time.Time   `json:"plannedDateEnd"`
-	Type             domain.Type `json:"type"`
+	CustomerID       uuid.UUID   `json:"customerID" validate:"required"`
+	Description      string      `json:"description" validate:"notblank"`
+	PlannedDateBegin time.Time   `json:"plannedDateBegin" validate:"required"`
+	PlannedDateEnd   time.Time   `json:"plannedDateEnd" validate:"required,gtfield=PlannedDateBegin"`
+	Type             domain.Type `json:"type" validate:"required,oneof='activar cliente' 'cancelar cliente'" enums:"activar cliente,cancelar cliente"`
+}
+
+// partial update, fields not sent stay the same. Only allowed while the order is new
+type UpdateWorkOrderRequest struct {
+	Description      *string    `json:"description" validate:"omitempty,notblank"`
+	PlannedDateBegin *time.Time `json:"plannedDateBegin"`
+	PlannedDateEnd   *time.Time `json:"plannedDateEnd"`
+}
+
+type CancelWorkOrderRequest struct {
+	Reason string `json:"reason" validate:"required,notblank"`
+}
+
+// two open orders of the same customer planned at the same time
+type WorkOrderConflictResponse struct {
+	WorkOrder     domain.WorkOrder `json:"work_order"`
+	ConflictsWith domain.WorkOrder `json:"conflicts_with"`
+}
+
+type WorkOrderConflictsResponse struct {
+	Data  []WorkOrderConflictResponse `json:"data"`
+	Total int                         `json:"total"`
+}
+
+// status changes of one order, oldest first
+type WorkOrderHistoryResponse struct {
+	Data  []domain.WorkOrderStatusChange `json:"data"`
+	Total int                            `json:"total"`
+}
+
+// fields edited on a work order, oldest first
+type WorkOrderChangesResponse struct {
+	Data  []domain.WorkOrderChangeLog `json:"data"`
+	Total int                         `json:"total"`
+}
+
+// periods a customer was active, oldest first, and the whole days adding all of them
+type CustomerServicePeriodsResponse struct {
+	Data       []domain.CustomerServicePeriod `json:"data"`
+	Total      int                            `json:"total"`
+	ActiveDays int                            `json:"active_days"`
 }
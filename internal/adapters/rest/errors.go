@@ -0,0 +1,87 @@
+// internal/adapters/rest/errors.go
+
+package rest
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+)
+
+const problemContentType = "application/problem+json"
+
+var (
+	ErrInvalidID   = domain.NewValidationError("invalid_id", "el ID enviado no es un UUID válido")
+	ErrInvalidBody = domain.NewValidationError("invalid_body", "cuerpo de la petición inválido")
+)
+
+// ProblemDetails is the body of every error response, RFC 7807. Code is stable, clients should
+// use it instead of Detail
+type ProblemDetails struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+	// one entry per invalid field, only when the code is invalid_fields
+	Errors []domain.FieldError `json:"errors,omitempty"`
+	// business rule that failed and its configured limit, ej. max_duration and 2h
+	Rule  string `json:"rule,omitempty"`
+	Limit string `json:"limit,omitempty"`
+}
+
+// ErrorHandler turns any error returned by a handler into a problem response, goes in fiber.Config
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	problem := ProblemDetails{
+		Type:     "about:blank",
+		Instance: c.OriginalURL(),
+	}
+
+	var domainErr *domain.Error
+	var fiberErr *fiber.Error
+	switch {
+	case errors.As(err, &domainErr):
+		problem.Status = statusOf(domainErr.Kind)
+		problem.Code = domainErr.Code
+		problem.Detail = domainErr.Message
+		problem.Errors = domainErr.Fields
+		problem.Rule = domainErr.Rule
+		problem.Limit = domainErr.Limit
+	case errors.As(err, &fiberErr):
+		// errors from fiber itself, ej. route not found
+		problem.Status = fiberErr.Code
+		problem.Code = strings.ReplaceAll(strings.ToLower(http.StatusText(fiberErr.Code)), " ", "_")
+		problem.Detail = fiberErr.Message
+	default:
+		// unexpected errors are logged, the client never sees the internals
+		log.Printf("Error interno en %s %s: %v", c.Method(), c.OriginalURL(), err)
+		problem.Status = fiber.StatusInternalServerError
+		problem.Code = "internal_error"
+		problem.Detail = "error interno del servidor"
+	}
+	problem.Title = http.StatusText(problem.Status)
+
+	return c.Status(problem.Status).JSON(problem, problemContentType)
+}
+
+func statusOf(kind domain.ErrorKind) int {
+	switch kind {
+	case domain.KindValidation:
+		return fiber.StatusBadRequest
+	case domain.KindNotFound:
+		return fiber.StatusNotFound
+	case domain.KindConflict:
+		return fiber.StatusConflict
+	case domain.KindUnprocessable:
+		return fiber.StatusUnprocessableEntity
+	case domain.KindPrecondition:
+		return fiber.StatusPreconditionFailed
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,36 @@
+// internal/adapters/rest/etag.go
+
+package rest
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+)
+
+var ErrInvalidIfMatch = domain.NewValidationError("invalid_if_match", "el header If-Match debe ser el ETag devuelto por la API, ej. \"3\"")
+
+// the ETag of a customer or work order is its version
+func setETag(c *fiber.Ctx, version int) {
+	c.Set(fiber.HeaderETag, `"`+strconv.Itoa(version)+`"`)
+}
+
+// version the client expects from If-Match, nil when the header is missing or is * so
+// clients that do not send it keep working
+func ifMatch(c *fiber.Ctx) (*int, error) {
+	value := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if value == "" || value == "*" {
+		return nil, nil
+	}
+	// weak or strong, the version is the same
+	value = strings.TrimPrefix(value, "W/")
+	value = strings.Trim(value, `"`)
+
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 1 {
+		return nil, ErrInvalidIfMatch
+	}
+	return &version, nil
+}
@@ -0,0 +1,124 @@
+// internal/adapters/rest/idempotency.go
+
+package rest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+)
+
+const (
+	// header with the key the client makes for each operation, the same in every retry
+	IdempotencyHeader = "Idempotency-Key"
+	// header set to true when the response is the stored one
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+)
+
+var (
+	ErrIdempotencyKeyInvalid  = domain.NewValidationError("idempotency_key_invalid", "el header Idempotency-Key debe tener entre 1 y 255 caracteres")
+	ErrIdempotencyKeyReused   = domain.NewUnprocessableError("idempotency_key_reused", "el Idempotency-Key ya se usó con una petición diferente")
+	ErrIdempotencyKeyInFlight = domain.NewConflictError("idempotency_key_in_progress", "la petición con este Idempotency-Key todavía se está procesando")
+)
+
+// headers of the response stored with the body, the rest are made again on each response
+var replayedHeaders = []string{fiber.HeaderETag, fiber.HeaderLocation}
+
+// Idempotency runs the request once per Idempotency-Key and replays the stored response to
+// retries with the same key, requests without the header are not touched. Responses with
+// status 5xx are not stored so the client can retry them. lockTimeout is how long the first
+// request holds the key, if it has not finished by then (ej. the server stopped) a retry runs it.
+// It must be longer than the slowest handler, a request that ends after a retry took its key
+// keeps its response out of the store and the client can see the operation done twice
+func Idempotency(store ports.IdempotencyStore, ttl, lockTimeout time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// fiber reuses the header, the stores can keep the key after the request
+		key := utils.CopyString(c.Get(IdempotencyHeader))
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > 255 {
+			return ErrIdempotencyKeyInvalid
+		}
+
+		fingerprint := requestFingerprint(c.Method(), c.Path(), c.Body())
+
+		now := time.Now()
+		stored, reserved, err := store.Reserve(c.Context(), domain.IdempotencyKey{
+			Key:         key,
+			Fingerprint: fingerprint,
+			LockedUntil: now.Add(lockTimeout),
+			ExpiresAt:   now.Add(ttl),
+		})
+		if err != nil {
+			return err
+		}
+
+		if !reserved {
+			switch {
+			case stored.Fingerprint != fingerprint:
+				// 422
+				return ErrIdempotencyKeyReused
+			case stored.CompletedAt == nil:
+				// 409, the first request did not finish yet
+				return ErrIdempotencyKeyInFlight
+			}
+			// same response as the first time
+			c.Set(IdempotencyReplayedHeader, "true")
+			c.Set(fiber.HeaderContentType, stored.ContentType)
+			for name, value := range stored.Headers {
+				c.Set(name, value)
+			}
+			return c.Status(stored.StatusCode).Send(stored.Body)
+		}
+
+		// errors are written here instead of after the chain so the response can be stored
+		if err := c.Next(); err != nil {
+			if errHandler := c.App().ErrorHandler(c, err); errHandler != nil {
+				if err := store.Release(c.Context(), *stored); err != nil {
+					log.Printf("Error liberando el Idempotency-Key %s: %v", key, err)
+				}
+				return errHandler
+			}
+		}
+
+		// the response is already written, a store error must not replace it
+		response := c.Response()
+		if response.StatusCode() >= fiber.StatusInternalServerError {
+			if err := store.Release(c.Context(), *stored); err != nil {
+				log.Printf("Error liberando el Idempotency-Key %s: %v", key, err)
+			}
+			return nil
+		}
+		// the body is reused by fiber, it is copied before saving
+		body := append([]byte(nil), response.Body()...)
+		headers := make(map[string]string)
+		for _, name := range replayedHeaders {
+			if value := response.Header.Peek(name); len(value) > 0 {
+				headers[name] = string(value)
+			}
+		}
+		completed, err := store.Complete(c.Context(), *stored, response.StatusCode(), string(response.Header.ContentType()), headers, body)
+		if err != nil {
+			log.Printf("Error guardando la respuesta del Idempotency-Key %s: %v", key, err)
+		} else if !completed {
+			// the lock expired and a retry took the key, its response is the one kept
+			log.Printf("El Idempotency-Key %s se liberó antes de terminar la petición, su respuesta no se guardó; IDEMPOTENCY_LOCK_TIMEOUT debe ser mayor que la petición más lenta", key)
+		}
+		return nil
+	}
+}
+
+// the same key on another endpoint or with another body is a different request
+func requestFingerprint(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,143 @@
+// internal/adapters/rest/idempotency_test.go
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/krud3/prueba-tecnica/internal/adapters/memory"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+)
+
+const (
+	testPath = "/api/v1/customers"
+	testBody = `{"firstName":"Ana"}`
+)
+
+// app with one create endpoint that counts how many times it ran
+func idempotencyApp(store ports.IdempotencyStore) (*fiber.App, *int) {
+	calls := 0
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Post(testPath, Idempotency(store, time.Hour, 30*time.Second), func(c *fiber.Ctx) error {
+		calls++
+		setETag(c, calls)
+		setLocation(c, uuid.New())
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"calls": calls})
+	})
+	return app, &calls
+}
+
+func send(t *testing.T, app *fiber.App, key string) (int, map[string]string, string) {
+	t.Helper()
+	req := httptest.NewRequest(fiber.MethodPost, testPath, strings.NewReader(testBody))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	req.Header.Set(IdempotencyHeader, key)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	headers := map[string]string{
+		fiber.HeaderETag:          resp.Header.Get(fiber.HeaderETag),
+		fiber.HeaderLocation:      resp.Header.Get(fiber.HeaderLocation),
+		IdempotencyReplayedHeader: resp.Header.Get(IdempotencyReplayedHeader),
+	}
+	return resp.StatusCode, headers, string(body)
+}
+
+func TestIdempotencyReplaysHeaders(t *testing.T) {
+	app, calls := idempotencyApp(memory.NewMemoryIdempotencyStore())
+
+	status, first, firstBody := send(t, app, "llave")
+	if status != fiber.StatusCreated {
+		t.Fatalf("first request: status %d", status)
+	}
+	status, replay, replayBody := send(t, app, "llave")
+	if status != fiber.StatusCreated || replayBody != firstBody {
+		t.Fatalf("replay: status %d body %s, want %d %s", status, replayBody, fiber.StatusCreated, firstBody)
+	}
+	if *calls != 1 {
+		t.Fatalf("the handler ran %d times, want 1", *calls)
+	}
+	if replay[IdempotencyReplayedHeader] != "true" {
+		t.Fatalf("%s: got %q, want true", IdempotencyReplayedHeader, replay[IdempotencyReplayedHeader])
+	}
+	for _, name := range []string{fiber.HeaderETag, fiber.HeaderLocation} {
+		if replay[name] == "" || replay[name] != first[name] {
+			t.Errorf("%s: replay %q, first response %q", name, replay[name], first[name])
+		}
+	}
+}
+
+// a request that reserved the key and never finished, ej. the server stopped, blocks the
+// retries only until its lock expires
+func TestIdempotencyUnfinishedRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		lockedUntil time.Duration
+		wantStatus  int
+		wantCalls   int
+	}{
+		{"locked", time.Minute, fiber.StatusConflict, 0},
+		{"lock expired", -time.Second, fiber.StatusCreated, 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			store := memory.NewMemoryIdempotencyStore()
+			now := time.Now()
+			_, _, err := store.Reserve(context.Background(), domain.IdempotencyKey{
+				Key:         "llave",
+				Fingerprint: requestFingerprint(fiber.MethodPost, testPath, []byte(testBody)),
+				LockedUntil: now.Add(tc.lockedUntil),
+				ExpiresAt:   now.Add(time.Hour),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			app, calls := idempotencyApp(store)
+			if status, _, body := send(t, app, "llave"); status != tc.wantStatus {
+				t.Fatalf("status %d, want %d: %s", status, tc.wantStatus, body)
+			}
+			if *calls != tc.wantCalls {
+				t.Fatalf("the handler ran %d times, want %d", *calls, tc.wantCalls)
+			}
+		})
+	}
+}
+
+// a request that ends after its lock expired and a retry took the key does not overwrite the
+// retry, the next one still waits for it
+func TestIdempotencySlowRequestKeepsTheRetry(t *testing.T) {
+	store := memory.NewMemoryIdempotencyStore()
+	fingerprint := requestFingerprint(fiber.MethodPost, testPath, []byte(testBody))
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	// the lock expires right away, the handler takes the key like a retry arriving meanwhile
+	app.Post(testPath, Idempotency(store, time.Hour, time.Nanosecond), func(c *fiber.Ctx) error {
+		time.Sleep(time.Millisecond)
+		now := time.Now()
+		// the handler runs in another goroutine, a 500 fails the test
+		if _, reserved, err := store.Reserve(c.Context(), domain.IdempotencyKey{Key: "llave", Fingerprint: fingerprint, LockedUntil: now.Add(time.Minute), ExpiresAt: now.Add(time.Hour)}); err != nil || !reserved {
+			return fmt.Errorf("the retry did not take the key: %v", err)
+		}
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"calls": 1})
+	})
+
+	if status, _, body := send(t, app, "llave"); status != fiber.StatusCreated {
+		t.Fatalf("slow request: status %d %s", status, body)
+	}
+	if status, _, body := send(t, app, "llave"); status != fiber.StatusConflict {
+		t.Fatalf("status %d %s, want %d while the retry is in progress", status, body, fiber.StatusConflict)
+	}
+}
@@ -0,0 +1,15 @@
+// internal/adapters/rest/location.go
+
+package rest
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Location of a resource created with POST on its collection, ej. /api/v1/customers/{id}
+func setLocation(c *fiber.Ctx, id uuid.UUID) {
+	c.Set(fiber.HeaderLocation, strings.TrimSuffix(c.Path(), "/")+"/"+id.String())
+}
@@ -0,0 +1,110 @@
+// internal/adapters/rest/pagination.go
+
+package rest
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+)
+
+const (
+	// page size when limit is not sent
+	defaultLimit = 50
+	// biggest page a client can ask for
+	maxLimit = 200
+)
+
+var (
+	ErrLimit  = domain.NewValidationError("invalid_limit", "valor de 'limit' inválido, debe ser un número entre 1 y 200")
+	ErrCursor = domain.NewValidationError("invalid_cursor", "valor de 'cursor' inválido, usar el next_cursor de la respuesta anterior")
+	// the cursor is (created_at, id), sorting by another column would need another cursor
+	ErrSort = domain.NewValidationError("invalid_sort", "valor de 'sort' inválido, solo se puede ordenar por fecha de creación: 'created_at' o '-created_at'")
+)
+
+// envelope for every list endpoint
+type PageResponse[T any] struct {
+	Data       []T     `json:"data"`
+	NextCursor *string `json:"next_cursor"`
+	Total      int64   `json:"total"`
+}
+
+// reads limit, cursor and sort from the query string
+func parsePagination(c *fiber.Ctx) (ports.Pagination, error) {
+	pagination := ports.Pagination{Limit: defaultLimit, Sort: ports.SortAsc}
+
+	// get limit value
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxLimit {
+			return pagination, ErrLimit
+		}
+		pagination.Limit = limit
+	}
+
+	// get sort value, - means descending
+	switch c.Query("sort") {
+	case "", "created_at":
+	case "-created_at":
+		pagination.Sort = ports.SortDesc
+	default:
+		return pagination, ErrSort
+	}
+
+	// get cursor value
+	if cursorStr := c.Query("cursor"); cursorStr != "" {
+		cursor, err := decodeCursor(cursorStr)
+		if err != nil {
+			return pagination, ErrCursor
+		}
+		pagination.Cursor = &cursor
+	}
+
+	return pagination, nil
+}
+
+// maps a page from the services to the response envelope
+func toPageResponse[T any](page ports.Page[T]) PageResponse[T] {
+	response := PageResponse[T]{Data: page.Items, Total: page.Total}
+	// empty list instead of null
+	if response.Data == nil {
+		response.Data = []T{}
+	}
+	if page.NextCursor != nil {
+		next := encodeCursor(*page.NextCursor)
+		response.NextCursor = &next
+	}
+	return response
+}
+
+// cursor is opaque for clients, base64 of "created_at|id"
+func encodeCursor(cursor ports.Cursor) string {
+	raw := cursor.CreatedAt.Format(time.RFC3339Nano) + "|" + cursor.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (ports.Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return ports.Cursor{}, err
+	}
+	createdAtStr, idStr, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return ports.Cursor{}, ErrCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtStr)
+	if err != nil {
+		return ports.Cursor{}, err
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return ports.Cursor{}, err
+	}
+	return ports.Cursor{CreatedAt: createdAt, ID: id}, nil
+}
@@ -0,0 +1,65 @@
+// internal/adapters/rest/pagination_test.go
+
+package rest
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+)
+
+func TestParsePaginationSort(t *testing.T) {
+	tests := []struct {
+		sort       string
+		wantStatus int
+		wantSort   ports.SortOrder
+	}{
+		{"", fiber.StatusOK, ports.SortAsc},
+		{"created_at", fiber.StatusOK, ports.SortAsc},
+		{"-created_at", fiber.StatusOK, ports.SortDesc},
+		{"planned_start", fiber.StatusBadRequest, ""},
+		{"-planned_date_begin", fiber.StatusBadRequest, ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.sort, func(t *testing.T) {
+			var got ports.Pagination
+			app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			app.Get("/", func(c *fiber.Ctx) error {
+				pagination, err := parsePagination(c)
+				if err != nil {
+					return err
+				}
+				got = pagination
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/?sort="+tc.sort, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tc.wantStatus {
+				t.Fatalf("status %d, want %d", resp.StatusCode, tc.wantStatus)
+			}
+			if tc.wantStatus == fiber.StatusOK {
+				if got.Sort != tc.wantSort {
+					t.Fatalf("sort %q, want %q", got.Sort, tc.wantSort)
+				}
+				return
+			}
+
+			// the problem tells the client which values it can use
+			var problem ProblemDetails
+			if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+				t.Fatal(err)
+			}
+			if problem.Code != ErrSort.Code || !strings.Contains(problem.Detail, "'created_at'") || !strings.Contains(problem.Detail, "'-created_at'") {
+				t.Fatalf("problem %+v, want code %s listing the allowed values", problem, ErrSort.Code)
+			}
+		})
+	}
+}
@@ -10,7 +10,8 @@ import (
 	_ "github.com/krud3/prueba-tecnica/docs"
 )
 
-func SetUpRoutes(app *fiber.App, customerHandler *CustomerHandler, workOrderHandler *WorkOrderHandler) {
+// idempotency goes only in the create endpoints, see Idempotency
+func SetUpRoutes(app *fiber.App, customerHandler *CustomerHandler, workOrderHandler *WorkOrderHandler, idempotency fiber.Handler) {
 	// display on console petitions using fiber logger middleware+
 	app.Use(logger.New())
 
@@ -27,17 +28,26 @@ func SetUpRoutes(app *fiber.App, customerHandler *CustomerHandler, workOrderHand
 
 	// ----- CUSTOMER
 	customers := api.Group("/customers")
-	customers.Post("/", customerHandler.Create)
+	customers.Post("/", idempotency, customerHandler.Create)
 	customers.Get("/active", customerHandler.GetActive)
 	customers.Get("/all", customerHandler.GetAll)
 	customers.Get("/:id", customerHandler.GetByID)
+	customers.Get("/:id/service-periods", customerHandler.GetServicePeriods)
+	customers.Patch("/:id", customerHandler.Update)
+	customers.Delete("/:id", customerHandler.Delete)
 
 	// ----- WORKORDER
 	workOrders := api.Group("/work-orders")
-	workOrders.Post("/", workOrderHandler.Create)
+	workOrders.Post("/", idempotency, workOrderHandler.Create)
 	workOrders.Get("/", workOrderHandler.GetFiltered)
+	// before /:id so conflicts is not read as an id
+	workOrders.Get("/conflicts", workOrderHandler.GetConflicts)
 	workOrders.Get("/:id", workOrderHandler.GetByID)
+	workOrders.Get("/:id/history", workOrderHandler.GetHistory)
+	workOrders.Get("/:id/changes", workOrderHandler.GetChanges)
+	workOrders.Patch("/:id", workOrderHandler.Update)
 	workOrders.Patch("/:id/complete", workOrderHandler.CompleteOrder)
+	workOrders.Patch("/:id/cancel", workOrderHandler.CancelOrder)
 
 	// ----- GET ALL ORDERS FROM A CLIENT
 	customers.Get("/:customerID/work-orders", workOrderHandler.GetByCustomerID)
@@ -0,0 +1,89 @@
+// internal/adapters/rest/validation.go
+
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+)
+
+// one validator for every request, it caches the rules of each struct
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// errors use the json name of the field, the one the client sent
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			return field.Name
+		}
+		return name
+	})
+
+	// like required but a string with only spaces is empty too
+	v.RegisterValidation("notblank", func(fl validator.FieldLevel) bool {
+		return strings.TrimSpace(fl.Field().String()) != ""
+	})
+
+	return v
+}
+
+// parses the body into req and checks its validate tags, answers invalid_body when the json
+// can not be parsed and invalid_fields with the list of problems when a rule fails
+func parseBody(c *fiber.Ctx, req any) error {
+	if err := c.BodyParser(req); err != nil {
+		return ErrInvalidBody
+	}
+
+	err := validate.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+
+	fields := make([]domain.FieldError, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		fields = append(fields, domain.FieldError{
+			Field:   fieldErr.Field(),
+			Code:    fieldErr.Tag(),
+			Message: messageOf(fieldErr),
+		})
+	}
+	return domain.NewFieldsError(fields)
+}
+
+// message in spanish for each rule used in dto.go
+func messageOf(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required", "notblank":
+		return "es obligatorio"
+	case "max":
+		return fmt.Sprintf("no puede tener más de %s caracteres", fieldErr.Param())
+	case "oneof":
+		return fmt.Sprintf("debe ser uno de: %s", fieldErr.Param())
+	case "gtfield":
+		return fmt.Sprintf("debe ser posterior a %s", jsonName(fieldErr.Param()))
+	default:
+		return "es inválido"
+	}
+}
+
+// gtfield gives the go name of the other field, json names start in lowercase
+func jsonName(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
@@ -3,7 +3,8 @@
 package rest
 
 import (
-	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,7 +12,6 @@ import (
 	"github.com/krud3/prueba-tecnica/internal/core/domain"
 	"github.com/krud3/prueba-tecnica/internal/core/ports"
 	"github.com/krud3/prueba-tecnica/internal/core/services"
-	"gorm.io/gorm"
 )
 
 type WorkOrderHandler struct {
@@ -25,25 +25,34 @@ func NewWorkOrderHandler(wS *services.WorkOrderService) *WorkOrderHandler {
 
 // Create crea una nueva orden de trabajo.
 // @Summary      Crea una nueva orden de trabajo
-// @Description  Crea una nueva orden para un cliente. Valida reglas de negocio como el estado del cliente y el intervalo de fechas.
+// @Description  Crea una nueva orden para un cliente. Valida reglas de negocio como el estado del cliente y las reglas de fechas configuradas (duración máxima, anticipación mínima y horario laboral).
 // @Tags         work-orders
 // @Accept       json
 // @Produce      json
+// @Param        Idempotency-Key header string false "Llave única de la operación, los reintentos con la misma llave reciben la primera respuesta"
+// @Param        X-Actor header string false "Usuario que crea la orden, queda en el historial"
 // @Param        workOrder body CreateWorkOrderRequest true "Datos de la Orden de Trabajo a crear"
 // @Success      201 {object} domain.WorkOrder
-// @Failure      400 {object} map[string]string "Error: Petición inválida"
-// @Failure      404 {object} map[string]string "Error: Cliente no encontrado"
-// @Failure      409 {object} map[string]string "Error: Conflicto de negocio (ej. cliente ya activo)"
-// @Failure      500 {object} map[string]string "Error: Error interno del servidor"
+// @Header       201 {string} ETag "Versión de la orden, usar en If-Match"
+// @Header       201 {string} Location "Ruta de la orden creada"
+// @Failure      400 {object} ProblemDetails "Error: Petición inválida"
+// @Failure      404 {object} ProblemDetails "Error: Cliente no encontrado"
+// @Failure      409 {object} ProblemDetails "Error: Conflicto de negocio (ej. cliente ya activo, ya tiene una orden abierta del mismo tipo o en el mismo horario)"
+// @Failure      422 {object} ProblemDetails "Error: Idempotency-Key usado con otra petición"
+// @Failure      500 {object} ProblemDetails "Error: Error interno del servidor"
 // @Router       /work-orders [post]
 func (wH *WorkOrderHandler) Create(c *fiber.Ctx) error {
 	// now with DTO
 	var req CreateWorkOrderRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cuerpo de la petición inválido"})
+	// 400 with the list of invalid fields
+	if err := parseBody(c, &req); err != nil {
+		return err
 	}
 
+	// id and status set here so the response has them
 	workOrder := domain.WorkOrder{
+		ID:               uuid.New(),
+		Status:           domain.StatusNew,
 		CustomerID:       req.CustomerID,
 		Description:      req.Description,
 		PlannedDateBegin: req.PlannedDateBegin,
@@ -51,35 +60,32 @@ func (wH *WorkOrderHandler) Create(c *fiber.Ctx) error {
 		Type:             req.Type,
 	}
 	// using handler to get the service to create workOrder
-	err := wH.wS.Create(c.Context(), workOrder)
-	if err != nil {
-		switch {
-		// handle custom errors
-		case errors.Is(err, services.ErrAA), errors.Is(err, services.ErrCC), errors.Is(err, services.ErrDateIntertal):
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
-		// handle customer not found
-		case errors.Is(err, gorm.ErrRecordNotFound):
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "el cliente especificado no existe"})
-		default:
-			// 500 default error
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
-		}
+	err := wH.wS.Create(c.Context(), &workOrder, actorFrom(c))
+	if err != nil {
+		// ErrorHandler answers with the status of the error
+		return err
 	}
+	setETag(c, workOrder.Version)
+	setLocation(c, workOrder.ID)
 	// 201 created
 	return c.Status(fiber.StatusCreated).JSON(workOrder)
 }
 
 // CompleteOrder completa una orden de trabajo.
 // @Summary      Completa una orden de trabajo
-// @Description  Marca una orden como 'done', lo que activa/desactiva al cliente asociado y envía un evento a Redis.
+// @Description  Marca una orden como 'done', lo que activa/desactiva al cliente asociado y envía un evento a Redis. El cambio queda en el historial de la orden.
 // @Tags         work-orders
 // @Produce      json
 // @Param        id path string true "ID de la Orden de Trabajo (UUID)"
+// @Param        X-Actor header string false "Usuario que completa la orden, queda en el historial"
+// @Param        If-Match header string false "ETag de la orden leída antes, si cambió se responde 412"
 // @Success      200 {object} map[string]string
-// @Failure      400 {object} map[string]string "Error: ID inválido"
-// @Failure      404 {object} map[string]string "Error: Orden no encontrada"
-// @Failure      409 {object} map[string]string "Error: Conflicto de estado (ej. la orden ya está completada)"
-// @Failure      500 {object} map[string]string "Error: Error interno del servidor"
+// @Header       200 {string} ETag "Nueva versión de la orden"
+// @Failure      400 {object} ProblemDetails "Error: ID inválido"
+// @Failure      404 {object} ProblemDetails "Error: Orden no encontrada"
+// @Failure      409 {object} ProblemDetails "Error: Conflicto de estado (ej. la orden ya está completada o el cliente ya está en el estado de la orden)"
+// @Failure      412 {object} ProblemDetails "Error: If-Match no coincide con la versión actual"
+// @Failure      500 {object} ProblemDetails "Error: Error interno del servidor"
 // @Router       /work-orders/{id}/complete [patch]
 func (wH *WorkOrderHandler) CompleteOrder(c *fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -87,29 +93,130 @@ func (wH *WorkOrderHandler) CompleteOrder(c *fiber.Ctx) error {
 	// verifies if id match uuid struct
 	if err != nil {
 		// 400
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "EL campo ID de la orden es inválido"})
+		return ErrInvalidID
+	}
+
+	// nil when the client does not send If-Match
+	version, err := ifMatch(c)
+	if err != nil {
+		return err
 	}
 
 	// the service try to CompleteOrder
-	err = wH.wS.CompleteOrder(c.Context(), workOrderID)
-	if err != nil {
-		switch {
-		// custom errors
-		case errors.Is(err, services.ErrWODone), errors.Is(err, services.ErrWOCancelled):
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
-		// not found
-		case errors.Is(err, gorm.ErrRecordNotFound):
-			// 404
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "orden no encontrada"})
-		default:
-			// 500
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
-		}
+	workOrder, err := wH.wS.CompleteOrder(c.Context(), workOrderID, actorFrom(c), version)
+	if err != nil {
+		// ErrorHandler answers with the status of the error
+		return err
 	}
+	setETag(c, workOrder.Version)
 	// 200 ok
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Orden completada exitosamente"})
 }
 
+// CancelOrder cancela una orden de trabajo.
+// @Summary      Cancela una orden de trabajo
+// @Description  Marca una orden como 'cancelled' guardando el motivo y envía un evento a Redis. El cliente asociado no cambia. El cambio queda en el historial de la orden.
+// @Tags         work-orders
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "ID de la Orden de Trabajo (UUID)"
+// @Param        X-Actor header string false "Usuario que cancela la orden, queda en el historial"
+// @Param        If-Match header string false "ETag de la orden leída antes, si cambió se responde 412"
+// @Param        cancellation body CancelWorkOrderRequest true "Motivo de la cancelación"
+// @Success      200 {object} map[string]string
+// @Header       200 {string} ETag "Nueva versión de la orden"
+// @Failure      400 {object} ProblemDetails "Error: ID o motivo inválido"
+// @Failure      404 {object} ProblemDetails "Error: Orden no encontrada"
+// @Failure      409 {object} ProblemDetails "Error: Conflicto de estado (ej. la orden ya está completada o cancelada)"
+// @Failure      412 {object} ProblemDetails "Error: If-Match no coincide con la versión actual"
+// @Failure      500 {object} ProblemDetails "Error: Error interno del servidor"
+// @Router       /work-orders/{id}/cancel [patch]
+func (wH *WorkOrderHandler) CancelOrder(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	workOrderID, err := uuid.Parse(idStr)
+	// verifies if id match uuid struct
+	if err != nil {
+		// 400
+		return ErrInvalidID
+	}
+
+	version, err := ifMatch(c)
+	if err != nil {
+		return err
+	}
+
+	var req CancelWorkOrderRequest
+	// 400 with the list of invalid fields
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	// the service try to CancelOrder
+	workOrder, err := wH.wS.CancelOrder(c.Context(), workOrderID, req.Reason, actorFrom(c), version)
+	if err != nil {
+		// ErrorHandler answers with the status of the error
+		return err
+	}
+	setETag(c, workOrder.Version)
+	// 200 ok
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Orden cancelada exitosamente"})
+}
+
+// Update modifica una orden de trabajo.
+// @Summary      Modifica o reprograma una orden de trabajo
+// @Description  Cambia la descripción y/o las fechas planeadas de una orden en estado 'new'. Si cambian las fechas valida de nuevo las reglas de negocio configuradas (duración máxima, anticipación mínima y horario laboral) y registra quién hizo cada cambio (header X-Actor).
+// @Tags         work-orders
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "ID de la Orden de Trabajo (UUID)"
+// @Param        X-Actor header string false "Usuario que hace el cambio"
+// @Param        If-Match header string false "ETag de la orden leída antes, si cambió se responde 412"
+// @Param        workOrder body UpdateWorkOrderRequest true "Campos de la Orden de Trabajo a modificar"
+// @Success      200 {object} domain.WorkOrder
+// @Header       200 {string} ETag "Nueva versión de la orden"
+// @Failure      400 {object} ProblemDetails "Error: Petición inválida"
+// @Failure      404 {object} ProblemDetails "Error: Orden no encontrada"
+// @Failure      409 {object} ProblemDetails "Error: Conflicto de negocio (ej. la orden ya está completada o cancelada, o se cruza con otra orden abierta)"
+// @Failure      412 {object} ProblemDetails "Error: If-Match no coincide con la versión actual"
+// @Failure      500 {object} ProblemDetails "Error: Error interno del servidor"
+// @Router       /work-orders/{id} [patch]
+func (wH *WorkOrderHandler) Update(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	workOrderID, err := uuid.Parse(idStr)
+	// verifies if id match uuid struct
+	if err != nil {
+		// 400
+		return ErrInvalidID
+	}
+
+	version, err := ifMatch(c)
+	if err != nil {
+		return err
+	}
+
+	var req UpdateWorkOrderRequest
+	// 400 with the list of invalid fields
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+	// map DTO to changes
+	changes := ports.WorkOrderChanges{
+		Description:      req.Description,
+		PlannedDateBegin: req.PlannedDateBegin,
+		PlannedDateEnd:   req.PlannedDateEnd,
+	}
+
+	// the service try to Edit
+	workOrder, err := wH.wS.Edit(c.Context(), workOrderID, changes, actorFrom(c), version)
+	if err != nil {
+		// ErrorHandler answers with the status of the error
+		return err
+	}
+	setETag(c, workOrder.Version)
+	// 200 ok
+	return c.Status(fiber.StatusOK).JSON(workOrder)
+}
+
 // GetByID busca una orden de trabajo por su ID.
 // @Summary      Busca una orden de trabajo por ID
 // @Description  Obtiene los detalles de una orden de trabajo, incluyendo la información del cliente embebida.
@@ -117,9 +224,10 @@ func (wH *WorkOrderHandler) CompleteOrder(c *fiber.Ctx) error {
 // @Produce      json
 // @Param        id path string true "ID de la Orden de Trabajo (UUID)"
 // @Success      200 {object} domain.WorkOrder
-// @Failure      400 {object} map[string]string "Error: ID inválido"
-// @Failure      404 {object} map[string]string "Error: Orden no encontrada"
-// @Failure      500 {object} map[string]string "Error: Error interno del servidor"
+// @Header       200 {string} ETag "Versión de la orden, usar en If-Match"
+// @Failure      400 {object} ProblemDetails "Error: ID inválido"
+// @Failure      404 {object} ProblemDetails "Error: Orden no encontrada"
+// @Failure      500 {object} ProblemDetails "Error: Error interno del servidor"
 // @Router       /work-orders/{id} [get]
 func (wH *WorkOrderHandler) GetByID(c *fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -127,72 +235,137 @@ func (wH *WorkOrderHandler) GetByID(c *fiber.Ctx) error {
 	// verifies if id match uuid struct
 	if err != nil {
 		// 400
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "EL campo ID de la orden es inválido"})
+		return ErrInvalidID
 	}
 
 	workOrder, err := wH.wS.FindByID(c.Context(), workOrderID)
-	// handle error
+	// handle error, 404 if not found
 	if err != nil {
-		//500
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
-	}
-	// empty?
-	if workOrder == nil {
-		// 404
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "orden no encontrada"})
+		return err
 	}
+	setETag(c, workOrder.Version)
 
 	// 200 ok
 	return c.Status(fiber.StatusOK).JSON(workOrder)
 }
 
+// GetHistory lista los cambios de estado de una orden de trabajo.
+// @Summary      Historial de estados de una orden de trabajo
+// @Description  Devuelve los cambios de estado de la orden del más antiguo al más reciente: quién la creó, completó o canceló y cuándo. OldStatus es null en la creación.
+// @Tags         work-orders
+// @Produce      json
+// @Param        id path string true "ID de la Orden de Trabajo (UUID)"
+// @Success      200 {object} WorkOrderHistoryResponse
+// @Failure      400 {object} ProblemDetails "Error: ID inválido"
+// @Failure      404 {object} ProblemDetails "Error: Orden no encontrada"
+// @Failure      500 {object} ProblemDetails "Error: Error interno del servidor"
+// @Router       /work-orders/{id}/history [get]
+func (wH *WorkOrderHandler) GetHistory(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	workOrderID, err := uuid.Parse(idStr)
+	// verifies if id match uuid struct
+	if err != nil {
+		// 400
+		return ErrInvalidID
+	}
+
+	history, err := wH.wS.History(c.Context(), workOrderID)
+	// handle error, 404 if not found
+	if err != nil {
+		return err
+	}
+
+	// 200 ok
+	return c.Status(fiber.StatusOK).JSON(WorkOrderHistoryResponse{Data: history, Total: len(history)})
+}
+
+// GetChanges lista los campos editados de una orden de trabajo.
+// @Summary      Cambios de campos de una orden de trabajo
+// @Description  Devuelve los cambios hechos con PATCH /work-orders/{id} del más antiguo al más reciente, un registro por campo: el campo (description, planned_date_begin o planned_date_end), el valor anterior, el nuevo, quién lo cambió (header X-Actor) y cuándo. Las fechas van en RFC3339.
+// @Tags         work-orders
+// @Produce      json
+// @Param        id path string true "ID de la Orden de Trabajo (UUID)"
+// @Success      200 {object} WorkOrderChangesResponse
+// @Failure      400 {object} ProblemDetails "Error: ID inválido"
+// @Failure      404 {object} ProblemDetails "Error: Orden no encontrada"
+// @Failure      500 {object} ProblemDetails "Error: Error interno del servidor"
+// @Router       /work-orders/{id}/changes [get]
+func (wH *WorkOrderHandler) GetChanges(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	workOrderID, err := uuid.Parse(idStr)
+	// verifies if id match uuid struct
+	if err != nil {
+		// 400
+		return ErrInvalidID
+	}
+
+	changes, err := wH.wS.Changes(c.Context(), workOrderID)
+	// handle error, 404 if not found
+	if err != nil {
+		return err
+	}
+
+	// 200 ok
+	return c.Status(fiber.StatusOK).JSON(WorkOrderChangesResponse{Data: changes, Total: len(changes)})
+}
+
 // GetFiltered busca órdenes de trabajo con filtros.
 // @Summary      Busca órdenes de trabajo con filtros
-// @Description  Obtiene una lista de órdenes de trabajo. Se puede filtrar por rango de fechas (since, until) y/o por estado (status).
+// @Description  Obtiene una página de órdenes de trabajo. Se puede filtrar por rango de fechas planeadas (since, until), estado (status), cliente (customerID), tipo (type), texto en la descripción (q) y rango de fecha de creación (createdSince, createdUntil). Los filtros se combinan entre sí. Usar next_cursor como cursor para pedir la siguiente página.
 // @Tags         work-orders
 // @Produce      json
-// @Param        since  query string false "Fecha de inicio (Formato RFC3339: 2024-07-30T10:00:00Z)"
-// @Param        until  query string false "Fecha de fin (Formato RFC3339: 2024-07-30T10:00:00Z)"
-// @Param        status query string false "Estado de la orden" Enums(new, done, cancelled)
-// @Success      200 {array} domain.WorkOrder
-// @Failure      400 {object} map[string]string "Error: Parámetro de filtro inválido"
-// @Failure      500 {object} map[string]string "Error: Error interno del servidor"
+// @Param        since        query string false "Fecha de inicio (Formato RFC3339: 2024-07-30T10:00:00Z)"
+// @Param        until        query string false "Fecha de fin (Formato RFC3339: 2024-07-30T10:00:00Z)"
+// @Param        status       query string false "Estado de la orden" Enums(new, done, cancelled)
+// @Param        customerID   query string false "ID del Cliente (UUID)"
+// @Param        type         query string false "Tipo de la orden" Enums(activar cliente, cancelar cliente)
+// @Param        q            query string false "Texto a buscar en la descripción (sin distinguir mayúsculas)"
+// @Param        createdSince query string false "Creada desde (Formato RFC3339: 2024-07-30T10:00:00Z)"
+// @Param        createdUntil query string false "Creada hasta (Formato RFC3339: 2024-07-30T10:00:00Z)"
+// @Param        limit  query int    false "Tamaño de la página (1-200, por defecto 50)"
+// @Param        cursor query string false "Cursor devuelto en next_cursor por la página anterior"
+// @Param        sort   query string false "Orden por fecha de creación, el único soportado. Otro valor responde 400" Enums(created_at, -created_at)
+// @Success      200 {object} PageResponse[domain.WorkOrder]
+// @Failure      400 {object} ProblemDetails "Error: Parámetro de filtro inválido"
+// @Failure      500 {object} ProblemDetails "Error: Error interno del servidor"
 // @Router       /work-orders [get]
 func (wH *WorkOrderHandler) GetFiltered(c *fiber.Ctx) error {
+	// get limit, cursor and sort values
+	pagination, err := parsePagination(c)
+	if err != nil {
+		// 400
+		return err
+	}
 	// struct ports.WorkOrderFilters
-	filters := ports.WorkOrderFilters{}
-	// get since value
-	sinceStr := c.Query("since")
-	if sinceStr != "" {
-		// verify time format
-		since, err := time.Parse(time.RFC3339, sinceStr)
-		if err != nil {
-			// 400
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "formato de fecha 'since' inválido, usar formato RFC3339 (YYYY-MM-DDTHH:MM:SSZ)",
-			})
-		}
-		filters.Since = &since
+	filters := ports.WorkOrderFilters{Pagination: pagination}
+	// get since, until, createdSince and createdUntil values
+	dates := []struct {
+		name   string
+		target **time.Time
+	}{
+		{"since", &filters.Since},
+		{"until", &filters.Until},
+		{"createdSince", &filters.CreatedSince},
+		{"createdUntil", &filters.CreatedUntil},
 	}
-
-	// get until value
-	untilStr := c.Query("until")
-	if untilStr != "" {
-		// verify time format
-		until, err := time.Parse(time.RFC3339, untilStr)
+	for _, date := range dates {
+		value, err := parseTimeQuery(c, date.name)
 		if err != nil {
 			// 400
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "formato de fecha 'until' inválido, usar formato RFC3339 (YYYY-MM-DDTHH:MM:SSZ)",
-			})
+			return err
 		}
-		filters.Until = &until
+		*date.target = value
 	}
 
 	// verifies if since > until
 	if filters.Since != nil && filters.Until != nil && filters.Since.After(*filters.Until) {
 		// 400
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "since no puede ser posterior a until"})
+		return domain.NewValidationError("invalid_date_range", "since no puede ser posterior a until")
+	}
+	// verifies if createdSince > createdUntil
+	if filters.CreatedSince != nil && filters.CreatedUntil != nil && filters.CreatedSince.After(*filters.CreatedUntil) {
+		// 400
+		return domain.NewValidationError("invalid_date_range", "createdSince no puede ser posterior a createdUntil")
 	}
 	// get status value
 	statusStr := c.Query("status")
@@ -201,49 +374,164 @@ func (wH *WorkOrderHandler) GetFiltered(c *fiber.Ctx) error {
 		// verifies if status is valid
 		if status != domain.StatusNew && status != domain.StatusDone && status != domain.StatusCancelled {
 			// 400
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "valor de 'status' inválido, debe ser 'new', 'done' o 'cancelled'",
-			})
+			return domain.NewValidationError("invalid_query", "valor de 'status' inválido, debe ser 'new', 'done' o 'cancelled'")
 		}
 		filters.Status = &status
 	}
 
+	// get customerID value
+	customerIDStr := c.Query("customerID")
+	if customerIDStr != "" {
+		customerID, err := uuid.Parse(customerIDStr)
+		if err != nil {
+			// 400
+			return domain.NewValidationError("invalid_query", "valor de 'customerID' inválido, debe ser un UUID")
+		}
+		filters.CustomerID = &customerID
+	}
+
+	// get type value
+	typeStr := c.Query("type")
+	if typeStr != "" {
+		woType := domain.Type(typeStr)
+		// verifies if type is valid
+		if woType != domain.TypeActivate && woType != domain.TypeCancell {
+			// 400
+			return domain.NewValidationError("invalid_query", "valor de 'type' inválido, debe ser 'activar cliente' o 'cancelar cliente'")
+		}
+		filters.Type = &woType
+	}
+
+	// get text to search in description
+	filters.Search = strings.TrimSpace(c.Query("q"))
+
 	// trying to find by filter using service
-	workOrders, err := wH.wS.FindByFilter(c.Context(), filters)
+	page, err := wH.wS.FindByFilter(c.Context(), filters)
 	if err != nil {
 		// 500 server error
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "error al buscar las órdenes de trabajo"})
+		return err
 	}
 
 	// 200 ok
-	return c.Status(fiber.StatusOK).JSON(workOrders)
+	return c.Status(fiber.StatusOK).JSON(toPageResponse(page))
+}
+
+// biggest range GetConflicts looks at, each order is compared with every other one in it
+const maxConflictsRange = 31 * 24 * time.Hour
+
+// GetConflicts lista órdenes de trabajo que se cruzan.
+// @Summary      Lista órdenes de trabajo con horarios cruzados
+// @Description  Devuelve los pares de órdenes abiertas ('new') del mismo cliente cuyas fechas planeadas se cruzan y que caen dentro del rango [since, until). El rango no puede ser mayor a 31 días.
+// @Tags         work-orders
+// @Produce      json
+// @Param        since      query string true  "Inicio del rango (Formato RFC3339: 2024-07-30T10:00:00Z)"
+// @Param        until      query string true  "Fin del rango (Formato RFC3339: 2024-07-30T10:00:00Z)"
+// @Param        customerID query string false "ID del Cliente (UUID)"
+// @Success      200 {object} WorkOrderConflictsResponse
+// @Failure      400 {object} ProblemDetails "Error: Rango o parámetro inválido"
+// @Failure      500 {object} ProblemDetails "Error: Error interno del servidor"
+// @Router       /work-orders/conflicts [get]
+func (wH *WorkOrderHandler) GetConflicts(c *fiber.Ctx) error {
+	since, err := parseTimeQuery(c, "since")
+	if err != nil {
+		// 400
+		return err
+	}
+	until, err := parseTimeQuery(c, "until")
+	if err != nil {
+		// 400
+		return err
+	}
+	// both are mandatory, the range bounds the comparison
+	if since == nil || until == nil {
+		return domain.NewValidationError("invalid_date_range", "'since' y 'until' son obligatorios")
+	}
+	if !until.After(*since) {
+		return domain.NewValidationError("invalid_date_range", "since debe ser anterior a until")
+	}
+	if until.Sub(*since) > maxConflictsRange {
+		return domain.NewValidationError("invalid_date_range", "el rango entre since y until no puede ser mayor a 31 días")
+	}
+
+	// get customerID value
+	var customerID *uuid.UUID
+	if customerIDStr := c.Query("customerID"); customerIDStr != "" {
+		id, err := uuid.Parse(customerIDStr)
+		if err != nil {
+			// 400
+			return domain.NewValidationError("invalid_query", "valor de 'customerID' inválido, debe ser un UUID")
+		}
+		customerID = &id
+	}
+
+	conflicts, err := wH.wS.FindConflicts(c.Context(), *since, *until, customerID)
+	if err != nil {
+		// 500 server error
+		return err
+	}
+
+	response := WorkOrderConflictsResponse{
+		Data:  make([]WorkOrderConflictResponse, 0, len(conflicts)),
+		Total: len(conflicts),
+	}
+	for _, conflict := range conflicts {
+		response.Data = append(response.Data, WorkOrderConflictResponse{
+			WorkOrder:     conflict.WorkOrder,
+			ConflictsWith: conflict.ConflictsWith,
+		})
+	}
+	// 200 ok or empty
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// reads an optional RFC3339 date from the query string, nil if it was not sent
+func parseTimeQuery(c *fiber.Ctx, name string) (*time.Time, error) {
+	valueStr := c.Query(name)
+	if valueStr == "" {
+		return nil, nil
+	}
+	// verify time format
+	value, err := time.Parse(time.RFC3339, valueStr)
+	if err != nil {
+		return nil, domain.NewValidationError("invalid_date", fmt.Sprintf("formato de fecha '%s' inválido, usar formato RFC3339 (YYYY-MM-DDTHH:MM:SSZ)", name))
+	}
+	return &value, nil
 }
 
 // GetByCustomerID busca órdenes de trabajo por ID de cliente.
 // @Summary      Busca órdenes de trabajo por ID de cliente
-// @Description  Obtiene una lista de todas las órdenes de trabajo asociadas a un cliente específico.
+// @Description  Obtiene una página de las órdenes de trabajo asociadas a un cliente específico. Usar next_cursor como cursor para pedir la siguiente página.
 // @Tags         work-orders, customers
 // @Produce      json
 // @Param        customerID path string true "ID del Cliente (UUID)"
-// @Success      200 {array} domain.WorkOrder
-// @Failure      400 {object} map[string]string "Error: ID de cliente inválido"
-// @Failure      500 {object} map[string]string "Error: Error interno del servidor"
+// @Param        limit  query int    false "Tamaño de la página (1-200, por defecto 50)"
+// @Param        cursor query string false "Cursor devuelto en next_cursor por la página anterior"
+// @Param        sort   query string false "Orden por fecha de creación, el único soportado. Otro valor responde 400" Enums(created_at, -created_at)
+// @Success      200 {object} PageResponse[domain.WorkOrder]
+// @Failure      400 {object} ProblemDetails "Error: ID de cliente o parámetro de paginación inválido"
+// @Failure      500 {object} ProblemDetails "Error: Error interno del servidor"
 // @Router       /customers/{customerID}/work-orders [get]
 func (wH *WorkOrderHandler) GetByCustomerID(c *fiber.Ctx) error {
 	idStr := c.Params("customerID")
 	customerID, err := uuid.Parse(idStr)
 	if err != nil {
 		// id given must match uuid struct
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de cliente inválido"})
+		return ErrInvalidID
+	}
+
+	pagination, err := parsePagination(c)
+	if err != nil {
+		// 400
+		return err
 	}
 
 	// trying to find using service
-	workOrders, err := wH.wS.FindByCustomerID(c.Context(), customerID)
+	page, err := wH.wS.FindByCustomerID(c.Context(), customerID, pagination)
 	if err != nil {
 		// 500 server error finding by customer id
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
 	// 200 ok
-	return c.Status(fiber.StatusOK).JSON(workOrders)
+	return c.Status(fiber.StatusOK).JSON(toPageResponse(page))
 }
@@ -0,0 +1,282 @@
+// internal/adapters/rest/workorder_handler_test.go
+
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/krud3/prueba-tecnica/internal/adapters/memory"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+	"github.com/krud3/prueba-tecnica/internal/core/services"
+)
+
+// the routes of the api on the memory adapters, without idempotency
+type handlerEnv struct {
+	app       *fiber.App
+	customers ports.CustomerRepository
+}
+
+func newHandlerEnv() *handlerEnv {
+	db := memory.NewDB()
+	customers := memory.NewMemoryCustomerRepository(db)
+	workOrders := memory.NewMemoryWorkOrderRepository(db)
+	uow := memory.NewMemoryUnitOfWork(db)
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	SetUpRoutes(app,
+		NewCustomerHandler(services.NewCustomerService(customers, uow)),
+		NewWorkOrderHandler(services.NewWorkOrderService(workOrders, customers, uow, services.DefaultPolicy())),
+		func(c *fiber.Ctx) error { return c.Next() },
+	)
+	return &handlerEnv{app: app, customers: customers}
+}
+
+// sends a json request as actor, an empty actor sends no X-Actor. Returns the status and the body
+func (e *handlerEnv) request(t *testing.T, method, path, actor, body string) (int, []byte) {
+	t.Helper()
+	headers := map[string]string{}
+	if actor != "" {
+		headers[ActorHeader] = actor
+	}
+	resp, data := e.do(t, method, path, headers, body)
+	return resp.StatusCode, data
+}
+
+// sends a json request with headers, the body of the response is already read
+func (e *handlerEnv) do(t *testing.T, method, path string, headers map[string]string, body string) (*http.Response, []byte) {
+	t.Helper()
+	req := httptest.NewRequest(method, "/api/v1"+path, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := e.app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, data
+}
+
+// the status and, when wantCode is set, the code of the problem
+func checkProblem(t *testing.T, status int, data []byte, wantStatus int, wantCode string) {
+	t.Helper()
+	if status != wantStatus {
+		t.Fatalf("status %d, want %d: %s", status, wantStatus, data)
+	}
+	if wantCode == "" {
+		return
+	}
+	var problem ProblemDetails
+	decode(t, data, &problem)
+	if problem.Code != wantCode {
+		t.Fatalf("code %q, want %q", problem.Code, wantCode)
+	}
+}
+
+func (e *handlerEnv) createCustomer(t *testing.T) domain.Customer {
+	t.Helper()
+	customer := domain.Customer{ID: uuid.New(), FirstName: "Ana", LastName: "Pérez", Address: "calle 1", Version: 1}
+	if err := e.customers.Create(context.Background(), customer); err != nil {
+		t.Fatalf("creating customer: %v", err)
+	}
+	return customer
+}
+
+// activation order of one hour two days from now, created by actor
+func (e *handlerEnv) createOrder(t *testing.T, customerID uuid.UUID, actor string) domain.WorkOrder {
+	t.Helper()
+	begin := time.Now().UTC().Truncate(time.Hour).Add(48 * time.Hour)
+	body := fmt.Sprintf(`{"customerID":%q,"description":"visita técnica","plannedDateBegin":%q,"plannedDateEnd":%q,"type":"activar cliente"}`,
+		customerID, begin.Format(time.RFC3339), begin.Add(time.Hour).Format(time.RFC3339))
+	status, data := e.request(t, fiber.MethodPost, "/work-orders", actor, body)
+	if status != fiber.StatusCreated {
+		t.Fatalf("creating work order: status %d %s", status, data)
+	}
+	var workOrder domain.WorkOrder
+	decode(t, data, &workOrder)
+	return workOrder
+}
+
+func decode(t *testing.T, data []byte, v any) {
+	t.Helper()
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("decoding %s: %v", data, err)
+	}
+}
+
+func TestGetChanges(t *testing.T) {
+	env := newHandlerEnv()
+	customer := env.createCustomer(t)
+	workOrder := env.createOrder(t, customer.ID, "ana")
+	untouched := env.createOrder(t, env.createCustomer(t).ID, "ana")
+
+	// the description and the end change, the begin is sent with the same value
+	begin := workOrder.PlannedDateBegin.UTC().Format(time.RFC3339)
+	end := workOrder.PlannedDateEnd.Add(30 * time.Minute).UTC().Format(time.RFC3339)
+	body := fmt.Sprintf(`{"description":"cambio de equipo","plannedDateBegin":%q,"plannedDateEnd":%q}`, begin, end)
+	if status, data := env.request(t, fiber.MethodPatch, "/work-orders/"+workOrder.ID.String(), "luis", body); status != fiber.StatusOK {
+		t.Fatalf("editing: status %d %s", status, data)
+	}
+
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+		// field, old value and new value of each change, oldest first
+		want [][3]string
+	}{
+		{"edited order", workOrder.ID.String(), fiber.StatusOK, [][3]string{
+			{"description", "visita técnica", "cambio de equipo"},
+			{"planned_date_end", workOrder.PlannedDateEnd.UTC().Format(time.RFC3339), end},
+		}},
+		{"order never edited", untouched.ID.String(), fiber.StatusOK, [][3]string{}},
+		{"unknown order", uuid.NewString(), fiber.StatusNotFound, nil},
+		{"invalid id", "123", fiber.StatusBadRequest, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, data := env.request(t, fiber.MethodGet, "/work-orders/"+tc.id+"/changes", "", "")
+			if status != tc.wantStatus {
+				t.Fatalf("status %d, want %d: %s", status, tc.wantStatus, data)
+			}
+			if tc.wantStatus != fiber.StatusOK {
+				return
+			}
+
+			// data is a list even without changes
+			var resp struct {
+				Data  *[]domain.WorkOrderChangeLog `json:"data"`
+				Total int                          `json:"total"`
+			}
+			decode(t, data, &resp)
+			if resp.Data == nil || resp.Total != len(*resp.Data) || len(*resp.Data) != len(tc.want) {
+				t.Fatalf("response %s, want %d changes", data, len(tc.want))
+			}
+			for i, change := range *resp.Data {
+				got := [3]string{change.Field, change.OldValue, change.NewValue}
+				if got != tc.want[i] || change.Actor != "luis" {
+					t.Fatalf("change %d: %v by %q, want %v by luis", i, got, change.Actor, tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGetHistory(t *testing.T) {
+	env := newHandlerEnv()
+	anonymous := env.createOrder(t, env.createCustomer(t).ID, "")
+	completed := env.createOrder(t, env.createCustomer(t).ID, "ana")
+	if status, data := env.request(t, fiber.MethodPatch, "/work-orders/"+completed.ID.String()+"/complete", "  luis ", ""); status != fiber.StatusOK {
+		t.Fatalf("completing: status %d %s", status, data)
+	}
+	cancelled := env.createOrder(t, env.createCustomer(t).ID, "ana")
+	if status, data := env.request(t, fiber.MethodPatch, "/work-orders/"+cancelled.ID.String()+"/cancel", "marta", `{"reason":"sin acceso"}`); status != fiber.StatusOK {
+		t.Fatalf("cancelling: status %d %s", status, data)
+	}
+
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+		// each step as old->new by actor, oldest first
+		want []string
+	}{
+		{"without X-Actor", anonymous.ID.String(), fiber.StatusOK, []string{"->new by anonymous"}},
+		{"completed", completed.ID.String(), fiber.StatusOK, []string{"->new by ana", "new->done by luis"}},
+		{"cancelled", cancelled.ID.String(), fiber.StatusOK, []string{"->new by ana", "new->cancelled by marta"}},
+		{"unknown order", uuid.NewString(), fiber.StatusNotFound, nil},
+		{"invalid id", "123", fiber.StatusBadRequest, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, data := env.request(t, fiber.MethodGet, "/work-orders/"+tc.id+"/history", "", "")
+			if status != tc.wantStatus {
+				t.Fatalf("status %d, want %d: %s", status, tc.wantStatus, data)
+			}
+			if tc.wantStatus != fiber.StatusOK {
+				var problem ProblemDetails
+				decode(t, data, &problem)
+				if problem.Status != tc.wantStatus {
+					t.Fatalf("problem %+v, want status %d", problem, tc.wantStatus)
+				}
+				return
+			}
+
+			var resp WorkOrderHistoryResponse
+			decode(t, data, &resp)
+			var got []string
+			for _, change := range resp.Data {
+				old := ""
+				if change.OldStatus != nil {
+					old = string(*change.OldStatus)
+				}
+				got = append(got, old+"->"+string(change.NewStatus)+" by "+change.Actor)
+			}
+			if resp.Total != len(resp.Data) || !slices.Equal(got, tc.want) {
+				t.Fatalf("history %v total %d, want %v", got, resp.Total, tc.want)
+			}
+		})
+	}
+}
+
+// the bodies of PATCH /work-orders/{id} and /cancel are checked before the service
+func TestWorkOrderBodies(t *testing.T) {
+	tests := []struct {
+		name string
+		// after /work-orders/{id}
+		path       string
+		body       string
+		wantStatus int
+		wantCode   string
+		// field of the only error, for invalid_fields
+		wantField string
+	}{
+		{"new description", "", `{"description":"cambio de equipo"}`, fiber.StatusOK, "", ""},
+		{"edit without fields", "", `{}`, fiber.StatusOK, "", ""},
+		{"blank description", "", `{"description":"   "}`, fiber.StatusBadRequest, "invalid_fields", "description"},
+		{"malformed edit", "", `{"description":`, fiber.StatusBadRequest, ErrInvalidBody.Code, ""},
+		{"cancel with reason", "/cancel", `{"reason":"el cliente no estaba"}`, fiber.StatusOK, "", ""},
+		{"cancel without reason", "/cancel", `{}`, fiber.StatusBadRequest, "invalid_fields", "reason"},
+		{"blank reason", "/cancel", `{"reason":"  "}`, fiber.StatusBadRequest, "invalid_fields", "reason"},
+		{"malformed cancel", "/cancel", `{"reason":`, fiber.StatusBadRequest, ErrInvalidBody.Code, ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			env := newHandlerEnv()
+			workOrder := env.createOrder(t, env.createCustomer(t).ID, "ana")
+
+			status, data := env.request(t, fiber.MethodPatch, "/work-orders/"+workOrder.ID.String()+tc.path, "ana", tc.body)
+			if status != tc.wantStatus {
+				t.Fatalf("status %d, want %d: %s", status, tc.wantStatus, data)
+			}
+			if tc.wantCode == "" {
+				return
+			}
+			var problem ProblemDetails
+			decode(t, data, &problem)
+			if problem.Code != tc.wantCode {
+				t.Fatalf("code %q, want %q", problem.Code, tc.wantCode)
+			}
+			if tc.wantField != "" && (len(problem.Errors) != 1 || problem.Errors[0].Field != tc.wantField) {
+				t.Fatalf("errors %+v, want one for %s", problem.Errors, tc.wantField)
+			}
+		})
+	}
+}
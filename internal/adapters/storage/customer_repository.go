@@ -4,16 +4,16 @@ package storage
 
 import (
 	"context"
-	"errors"
 
 	"github.com/google/uuid"
 	"github.com/krud3/prueba-tecnica/internal/core/domain"
 	"github.com/krud3/prueba-tecnica/internal/core/ports"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var (
-	ErrNoCID = errors.New("no se encontró ID asociada al customer")
+	ErrNoCID = domain.NewValidationError("customer_id_required", "no se encontró ID asociada al customer")
 )
 
 type gormCustomerRepository struct {
@@ -33,7 +33,7 @@ func (r *gormCustomerRepository) Create(ctx context.Context, customer domain.Cus
 	result := r.db.WithContext(ctx).Create(&customer)
 
 	// if any error return else nil
-	return result.Error
+	return mapError(result.Error, domain.ErrCustomerNotFound)
 }
 
 func (r *gormCustomerRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Customer, error) {
@@ -43,40 +43,76 @@ func (r *gormCustomerRepository) FindByID(ctx context.Context, id uuid.UUID) (*d
 	// assings customer by pointer
 	result := r.db.WithContext(ctx).First(&customer, "id = ?", id)
 	if result.Error != nil {
-		// if error
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, nil
-		}
-		// if not found
-		return nil, result.Error
+		// not found or any other error
+		return nil, mapError(result.Error, domain.ErrCustomerNotFound)
 	}
 	// founded
 	return &customer, nil
 }
 
-func (r *gormCustomerRepository) GetActive(ctx context.Context) ([]domain.Customer, error) {
+func (r *gormCustomerRepository) GetActive(ctx context.Context, pagination ports.Pagination) (ports.Page[domain.Customer], error) {
 	// to storages customers
 	var customers []domain.Customer
 
-	// search is active and stores it catch error if error
-	err := r.db.WithContext(ctx).Where("is_active = ?", true).Find(&customers).Error
+	// search is active and stores one page of them, catch error if error
+	query := r.db.WithContext(ctx).Model(&domain.Customer{}).Where("is_active = ?", true)
+	total, err := paginate(query, pagination, &customers)
+	if err != nil {
+		return ports.Page[domain.Customer]{}, mapError(err, domain.ErrCustomerNotFound)
+	}
 
 	// results
-	return customers, err
+	return pageOf(customers, pagination, total, customerCursor), nil
 }
 
-func (r *gormCustomerRepository) GetAll(ctx context.Context) ([]domain.Customer, error) {
+func (r *gormCustomerRepository) GetAll(ctx context.Context, pagination ports.Pagination) (ports.Page[domain.Customer], error) {
 	var customers []domain.Customer
 
-	err := r.db.WithContext(ctx).Find(&customers).Error
+	query := r.db.WithContext(ctx).Model(&domain.Customer{})
+	total, err := paginate(query, pagination, &customers)
+	if err != nil {
+		return ports.Page[domain.Customer]{}, mapError(err, domain.ErrCustomerNotFound)
+	}
 
-	return customers, err
+	return pageOf(customers, pagination, total, customerCursor), nil
 }
 
 func (r *gormCustomerRepository) Update(ctx context.Context, customer domain.Customer) error {
 	if customer.ID == uuid.Nil {
 		return ErrNoCID
-	} else {
-		return r.db.WithContext(ctx).Save(customer).Error
 	}
+
+	// only the row with the version that was read is updated, optimistic concurrency
+	version := customer.Version
+	customer.Version++
+	result := r.db.WithContext(ctx).
+		Model(&customer).
+		Select("*").
+		Omit("CreatedAt", clause.Associations).
+		Where("version = ?", version).
+		Updates(&customer)
+	if result.Error != nil {
+		return mapError(result.Error, domain.ErrCustomerNotFound)
+	}
+	// changed or deleted by someone else since it was read
+	if result.RowsAffected == 0 {
+		return domain.ErrVersionConflict
+	}
+	return nil
+}
+
+func (r *gormCustomerRepository) Delete(ctx context.Context, id uuid.UUID, version int) error {
+	if id == uuid.Nil {
+		return ErrNoCID
+	}
+	// DeletedAt makes gorm set deleted_at instead of removing the row, only the version that was read
+	result := r.db.WithContext(ctx).Where("version = ?", version).Delete(&domain.Customer{}, "id = ?", id)
+	if result.Error != nil {
+		return mapError(result.Error, domain.ErrCustomerNotFound)
+	}
+	// changed or deleted by someone else since it was read
+	if result.RowsAffected == 0 {
+		return domain.ErrVersionConflict
+	}
+	return nil
 }
@@ -0,0 +1,49 @@
+// internal/adapters/storage/customer_service_period_repository.go
+
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+	"gorm.io/gorm"
+)
+
+type gormCustomerServicePeriodRepository struct {
+	db *gorm.DB
+}
+
+func NewGormCustomerServicePeriodRepository(db *gorm.DB) ports.CustomerServicePeriodRepository {
+	return &gormCustomerServicePeriodRepository{db: db}
+}
+
+func (r *gormCustomerServicePeriodRepository) Open(ctx context.Context, period domain.CustomerServicePeriod) error {
+	if period.ID == uuid.Nil {
+		period.ID = uuid.New()
+	}
+	// the customer must exist, the unique index allows one open period per customer
+	return mapError(r.db.WithContext(ctx).Create(&period).Error, domain.ErrCustomerNotFound)
+}
+
+func (r *gormCustomerServicePeriodRepository) Close(ctx context.Context, customerID uuid.UUID, endedAt time.Time, workOrderID uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&domain.CustomerServicePeriod{}).
+		Where("customer_id = ? AND ended_at IS NULL", customerID).
+		Updates(map[string]any{"ended_at": endedAt, "end_work_order_id": workOrderID})
+	return mapError(result.Error, domain.ErrCustomerNotFound)
+}
+
+func (r *gormCustomerServicePeriodRepository) FindByCustomerID(ctx context.Context, customerID uuid.UUID) ([]domain.CustomerServicePeriod, error) {
+	periods := []domain.CustomerServicePeriod{}
+
+	// oldest first, reads like a timeline
+	err := r.db.WithContext(ctx).
+		Where("customer_id = ?", customerID).
+		Order("started_at, id").
+		Find(&periods).Error
+
+	return periods, err
+}
@@ -2,29 +2,51 @@
 package storage
 
 import (
-	"fmt"
-	"os"
+	"errors"
 
+	"github.com/krud3/prueba-tecnica/internal/config"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-func NewGormDB() (*gorm.DB, error) {
-	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		os.Getenv("DB_HOST"), os.Getenv("DB_PORT"),
-		os.Getenv("DB_USER"), os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_NAME"),
-	)
+// the sqlite driver is written in c, binaries built with CGO_ENABLED=0 only have postgres
+var errSQLiteNeedsCgo = errors.New("STORAGE_DRIVER=sqlite necesita compilar con CGO_ENABLED=1")
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+// NewGormDB opens the database of driver, postgres or sqlite. sqlite uses cfg.Path, a file or
+// :memory:, and is only in binaries built with cgo, see sqlite.go
+func NewGormDB(driver string, cfg config.DatabaseConfig) (*gorm.DB, error) {
+	dialector := postgres.Open(cfg.DSN())
+	if driver == "sqlite" {
+		var err error
+		dialector, err = newSQLiteDialector(cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
+		// gorm.ErrDuplicatedKey and gorm.ErrForeignKeyViolated instead of driver errors, see mapError
+		TranslateError: true,
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
+	if driver == "sqlite" {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		// sqlite writes one at a time and every connection to :memory: is a new database
+		sqlDB.SetMaxOpenConns(1)
+	}
+
 	return db, nil
 }
+
+func isSQLite(db *gorm.DB) bool {
+	return db.Dialector.Name() == "sqlite"
+}
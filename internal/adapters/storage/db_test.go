@@ -0,0 +1,64 @@
+// internal/adapters/storage/db_test.go
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/krud3/prueba-tecnica/internal/config"
+	"github.com/krud3/prueba-tecnica/migrations"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// runs fn on a migrated sqlite database in a temporary file and, when DATABASE_URL is set, on
+// that postgres. The postgres one is migrated up and kept, use a database only for tests
+func forEachDatabase(t *testing.T, fn func(t *testing.T, db *gorm.DB)) {
+	t.Run("sqlite", func(t *testing.T) {
+		db, err := NewGormDB("sqlite", config.DatabaseConfig{Path: filepath.Join(t.TempDir(), "test.db")})
+		if errors.Is(err, errSQLiteNeedsCgo) {
+			t.Skip("sqlite necesita cgo")
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		fn(t, migrated(t, db, "sqlite"))
+	})
+
+	t.Run("postgres", func(t *testing.T) {
+		url := os.Getenv("DATABASE_URL")
+		if url == "" {
+			t.Skip("DATABASE_URL no está definida")
+		}
+		db, err := gorm.Open(postgres.Open(url), &gorm.Config{TranslateError: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		fn(t, migrated(t, db, "postgres"))
+	})
+}
+
+// applies every migration of driver, without the query log
+func migrated(t *testing.T, db *gorm.DB, driver string) *gorm.DB {
+	t.Helper()
+	db = db.Session(&gorm.Session{Logger: logger.Discard})
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	migrator, err := NewGormMigrator(db, migrations.For(driver))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+	return db
+}
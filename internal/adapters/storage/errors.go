@@ -0,0 +1,40 @@
+// internal/adapters/storage/errors.go
+
+package storage
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"gorm.io/gorm"
+)
+
+// exclusion_violation, gorm does not translate it
+const exclusionViolation = "23P01"
+
+// translates gorm errors to domain errors so nothing from gorm leaves the repositories,
+// notFound is what the caller looked for. Needs TranslateError in the gorm config
+func mapError(err error, notFound *domain.Error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return notFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return domain.ErrDuplicated
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return domain.ErrRelatedNotFound
+	case isPgError(err, exclusionViolation), isSQLiteTriggerError(err):
+		// the only exclusion constraint is the one on the planned window of open orders, on
+		// sqlite the triggers that emulate it
+		return domain.ErrScheduleOverlap
+	default:
+		return err
+	}
+}
+
+func isPgError(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
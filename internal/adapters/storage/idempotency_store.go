@@ -0,0 +1,80 @@
+// internal/adapters/storage/idempotency_store.go
+
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type gormIdempotencyStore struct {
+	db *gorm.DB
+}
+
+func NewGormIdempotencyStore(db *gorm.DB) ports.IdempotencyStore {
+	return &gormIdempotencyStore{db: db}
+}
+
+func (s *gormIdempotencyStore) Reserve(ctx context.Context, key domain.IdempotencyKey) (*domain.IdempotencyKey, bool, error) {
+	var stored domain.IdempotencyKey
+	reserved := false
+	// postgres keeps microseconds, Complete and Release compare the lock with the saved one
+	key.LockedUntil = key.LockedUntil.Truncate(time.Microsecond)
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// an expired or abandoned key can be used again, see IdempotencyKey.Abandoned
+		now := time.Now()
+		if err := tx.Where("key = ? AND (expires_at <= ? OR (completed_at IS NULL AND locked_until <= ?))", key.Key, now, now).Delete(&domain.IdempotencyKey{}).Error; err != nil {
+			return err
+		}
+
+		// the primary key decides who runs the request when two retries arrive together
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&key)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 1 {
+			reserved = true
+			stored = key
+			return nil
+		}
+		return tx.First(&stored, "key = ?", key.Key).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return &stored, reserved, nil
+}
+
+func (s *gormIdempotencyStore) Complete(ctx context.Context, reserved domain.IdempotencyKey, statusCode int, contentType string, headers map[string]string, body []byte) (bool, error) {
+	completedAt := time.Now()
+	// Updates with a struct so Headers goes through its json serializer
+	result := s.db.WithContext(ctx).
+		Model(&domain.IdempotencyKey{}).
+		Scopes(heldBy(reserved)).
+		Select("StatusCode", "ContentType", "Headers", "Body", "CompletedAt").
+		Updates(&domain.IdempotencyKey{
+			StatusCode:  statusCode,
+			ContentType: contentType,
+			Headers:     headers,
+			Body:        body,
+			CompletedAt: &completedAt,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (s *gormIdempotencyStore) Release(ctx context.Context, reserved domain.IdempotencyKey) error {
+	return s.db.WithContext(ctx).Scopes(heldBy(reserved)).Delete(&domain.IdempotencyKey{}).Error
+}
+
+// the key while it is held by reserved, like IdempotencyKey.HeldBy
+func heldBy(reserved domain.IdempotencyKey) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("key = ? AND fingerprint = ? AND locked_until = ? AND completed_at IS NULL", reserved.Key, reserved.Fingerprint, reserved.LockedUntil)
+	}
+}
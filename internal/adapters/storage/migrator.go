@@ -0,0 +1,332 @@
+// internal/adapters/storage/migrator.go
+
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// same table and columns golang-migrate uses, databases migrated with its cli keep working
+const schemaTable = "schema_migrations"
+
+// key of the advisory lock, two instances starting at the same time do not migrate twice
+const migrationLockKey = 7_413_208_551
+
+// 001_create_initial_tables.up.sql
+var migrationFile = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+var ErrDirty = errors.New("la base de datos quedó a mitad de una migración, revisarla y usar migrate force <versión>")
+
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Migration
+	Applied bool
+}
+
+// Migrator applies the sql files of migrations/, the table keeps only the current version like
+// golang-migrate. Each migration runs in its own transaction with the new version, a failure
+// leaves the database in the previous one
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// files has pairs of NNN_name.up.sql and NNN_name.down.sql, other files are skipped
+func NewGormMigrator(db *gorm.DB, files fs.FS) (*Migrator, error) {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint]*Migration)
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("versión inválida en %s", entry.Name())
+		}
+		body, err := fs.ReadFile(files, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[uint(version)]
+		if !ok {
+			migration = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[uint(version)] = migration
+		}
+		if match[3] == "up" {
+			migration.Up = string(body)
+		} else {
+			migration.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("la migración %03d_%s necesita los archivos up y down", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Latest is the version of the last migration file, 0 if there are none
+func (m *Migrator) Latest() uint {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version is the current version of the database, 0 when nothing was applied
+func (m *Migrator) Version(ctx context.Context) (version uint, dirty bool, err error) {
+	err = m.withLock(ctx, func(conn *sql.Conn) error {
+		version, dirty, err = readVersion(ctx, conn)
+		return err
+	})
+	return version, dirty, err
+}
+
+// Status lists every migration and whether it is applied
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	version, _, err := m.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status = append(status, MigrationStatus{Migration: migration, Applied: migration.Version <= version})
+	}
+	return status, nil
+}
+
+// Up applies every pending migration and returns the ones applied
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	return m.Goto(ctx, m.Latest())
+}
+
+// Down reverts the last steps migrations and returns the ones reverted
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		version, err := checkedVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		index := m.indexOf(version)
+		for step := 0; step < steps && index >= 0; step++ {
+			if err := m.revert(ctx, conn, index); err != nil {
+				return err
+			}
+			reverted = append(reverted, m.migrations[index])
+			index--
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Goto applies or reverts migrations until the database is in version, 0 reverts everything
+func (m *Migrator) Goto(ctx context.Context, version uint) ([]Migration, error) {
+	if version != 0 && m.find(version) < 0 {
+		return nil, fmt.Errorf("no existe la migración %d", version)
+	}
+
+	var changed []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		current, err := checkedVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		// up to version
+		for index, migration := range m.migrations {
+			if migration.Version <= current || migration.Version > version {
+				continue
+			}
+			if err := m.apply(ctx, conn, index); err != nil {
+				return err
+			}
+			changed = append(changed, migration)
+		}
+		// or down to version
+		for index := m.indexOf(current); index >= 0 && m.migrations[index].Version > version; index-- {
+			if err := m.revert(ctx, conn, index); err != nil {
+				return err
+			}
+			changed = append(changed, m.migrations[index])
+		}
+		return nil
+	})
+	return changed, err
+}
+
+// Force sets the version without running anything and clears the dirty flag, used to fix the
+// database by hand after a failed migration
+func (m *Migrator) Force(ctx context.Context, version uint) error {
+	if version != 0 && m.find(version) < 0 {
+		return fmt.Errorf("no existe la migración %d", version)
+	}
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		if err := writeVersion(ctx, tx, version); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, index int) error {
+	migration := m.migrations[index]
+	if err := m.run(ctx, conn, migration.Up, migration.Version); err != nil {
+		return fmt.Errorf("migración %03d_%s up: %w", migration.Version, migration.Name, err)
+	}
+	return nil
+}
+
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, index int) error {
+	migration := m.migrations[index]
+	// the version before this one, 0 when it is the first
+	var previous uint
+	if index > 0 {
+		previous = m.migrations[index-1].Version
+	}
+	if err := m.run(ctx, conn, migration.Down, previous); err != nil {
+		return fmt.Errorf("migración %03d_%s down: %w", migration.Version, migration.Name, err)
+	}
+	return nil
+}
+
+// runs body and saves version in the same transaction
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, body string, version uint) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// without arguments the driver uses the simple protocol, files can have several statements
+	if _, err := tx.ExecContext(ctx, body); err != nil {
+		return err
+	}
+	if err := writeVersion(ctx, tx, version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// position of version in migrations, -1 if it is not there
+func (m *Migrator) find(version uint) int {
+	for index, migration := range m.migrations {
+		if migration.Version == version {
+			return index
+		}
+	}
+	return -1
+}
+
+// position of the last migration with a version up to version, -1 if none
+func (m *Migrator) indexOf(version uint) int {
+	index := -1
+	for i, migration := range m.migrations {
+		if migration.Version <= version {
+			index = i
+		}
+	}
+	return index
+}
+
+// runs fn with one connection that holds the advisory lock on postgres, the schema table is
+// created if it does not exist
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	sqlDB, err := m.db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// sqlite has no advisory locks, it is a local file or :memory: that one process uses
+	if !isSQLite(m.db) {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+			return err
+		}
+		defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockKey)
+	}
+
+	if _, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+schemaTable+" (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)"); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func readVersion(ctx context.Context, conn *sql.Conn) (uint, bool, error) {
+	var version int64
+	var dirty bool
+	err := conn.QueryRowContext(ctx, "SELECT version, dirty FROM "+schemaTable+" LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	// golang-migrate saves -1 when everything was reverted
+	if version < 0 {
+		return 0, dirty, nil
+	}
+	return uint(version), dirty, nil
+}
+
+// current version, ErrDirty when a migration did not finish
+func checkedVersion(ctx context.Context, conn *sql.Conn) (uint, error) {
+	version, dirty, err := readVersion(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("versión %d: %w", version, ErrDirty)
+	}
+	return version, nil
+}
+
+// one row with the current version, none when it is 0
+func writeVersion(ctx context.Context, tx *sql.Tx, version uint) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+schemaTable); err != nil {
+		return err
+	}
+	if version == 0 {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, "INSERT INTO "+schemaTable+" (version, dirty) VALUES ($1, false)", int64(version))
+	return err
+}
@@ -0,0 +1,118 @@
+// internal/adapters/storage/migrator_test.go
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/krud3/prueba-tecnica/internal/config"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/migrations"
+	"gorm.io/gorm/logger"
+)
+
+// the history backfilled by 012 ends in the status each order already had, the repeated open
+// orders 010 cancelled and the overlapping ones 011 cancelled included. Only sqlite, going back to 009 would drop the data of a shared
+// postgres
+func TestBackfilledHistoryEndsInTheCurrentStatus(t *testing.T) {
+	ctx := context.Background()
+	db, err := NewGormDB("sqlite", config.DatabaseConfig{Path: filepath.Join(t.TempDir(), "test.db")})
+	if errors.Is(err, errSQLiteNeedsCgo) {
+		t.Skip("sqlite necesita cgo")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Logger = logger.Discard
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	migrator, err := NewGormMigrator(db, migrations.For("sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// before the unique index of open orders and the history
+	if _, err := migrator.Goto(ctx, 9); err != nil {
+		t.Fatalf("migrate to 009: %v", err)
+	}
+
+	newCustomer := func() uuid.UUID {
+		t.Helper()
+		id := uuid.New()
+		if err := db.Exec("INSERT INTO customers (id, first_name, last_name, address) VALUES (?, 'Ana', 'Pérez', 'calle 1')", id).Error; err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	created := time.Now().UTC().Add(-time.Hour)
+	begin := created.Add(48 * time.Hour)
+	// one hour window starting start after begin, created offset after created
+	insert := func(customerID uuid.UUID, status domain.Status, woType domain.Type, start, offset time.Duration) uuid.UUID {
+		t.Helper()
+		id := uuid.New()
+		err := db.Exec("INSERT INTO work_orders (id, customer_id, description, planned_date_begin, planned_date_end, status, type, created_at) VALUES (?, ?, 'instalación', ?, ?, ?, ?, ?)",
+			id, customerID, begin.Add(start), begin.Add(start+time.Hour), status, woType, created.Add(offset)).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	customerID := newCustomer()
+	kept := insert(customerID, domain.StatusNew, domain.TypeActivate, 0, 0)
+	duplicated := insert(customerID, domain.StatusNew, domain.TypeActivate, 0, time.Minute)
+	done := insert(customerID, domain.StatusDone, domain.TypeCancell, 0, 2*time.Minute)
+	// the later one is cancelled even if its window starts first
+	overlapping := newCustomer()
+	older := insert(overlapping, domain.StatusNew, domain.TypeActivate, 30*time.Minute, 0)
+	overlapped := insert(overlapping, domain.StatusNew, domain.TypeCancell, 0, time.Minute)
+	// one starts when the other ends
+	adjacent := newCustomer()
+	first := insert(adjacent, domain.StatusNew, domain.TypeActivate, 0, 0)
+	next := insert(adjacent, domain.StatusNew, domain.TypeCancell, time.Hour, time.Minute)
+
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+
+	repo := NewGormWorkOrderStatusChangeRepository(db)
+	tests := []struct {
+		name      string
+		id        uuid.UUID
+		want      []domain.Status
+		wantActor string
+	}{
+		{"open order", kept, []domain.Status{domain.StatusNew}, "system"},
+		{"cancelled by 010", duplicated, []domain.Status{domain.StatusNew, domain.StatusCancelled}, "migration"},
+		{"done before 012", done, []domain.Status{domain.StatusNew, domain.StatusDone}, "system"},
+		{"older of an overlapping pair", older, []domain.Status{domain.StatusNew}, "system"},
+		{"cancelled by 011", overlapped, []domain.Status{domain.StatusNew, domain.StatusCancelled}, "migration"},
+		{"window before the next one", first, []domain.Status{domain.StatusNew}, "system"},
+		{"window after the previous one", next, []domain.Status{domain.StatusNew}, "system"},
+	}
+	for _, tc := range tests {
+		changes, err := repo.FindByWorkOrderID(ctx, tc.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []domain.Status
+		for _, change := range changes {
+			got = append(got, change.NewStatus)
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Fatalf("%s: history %v, want %v", tc.name, got, tc.want)
+		}
+		last := changes[len(changes)-1]
+		if last.Actor != tc.wantActor || (len(changes) > 1 && (last.OldStatus == nil || *last.OldStatus != domain.StatusNew)) {
+			t.Fatalf("%s: last change by %q from %v, want %q from new", tc.name, last.Actor, last.OldStatus, tc.wantActor)
+		}
+	}
+}
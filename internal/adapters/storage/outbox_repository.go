@@ -0,0 +1,80 @@
+// internal/adapters/storage/outbox_repository.go
+
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type gormOutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewGormOutboxRepository(db *gorm.DB) ports.OutboxRepository {
+	return &gormOutboxRepository{db: db}
+}
+
+func (r *gormOutboxRepository) Create(ctx context.Context, message domain.OutboxMessage) error {
+	if message.ID == uuid.Nil {
+		message.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(&message).Error
+}
+
+func (r *gormOutboxRepository) FindPending(ctx context.Context, limit int) ([]domain.OutboxMessage, error) {
+	var messages []domain.OutboxMessage
+
+	// oldest first so consumers receive events in the order they happened
+	query := r.db.WithContext(ctx).
+		Where("published_at IS NULL").
+		Order("created_at, id").
+		Limit(limit)
+	// in a unit of work the rows stay locked until it ends and another relay skips them. Sqlite
+	// has no row locks, it already lets one writer at a time
+	locking := r.db.Dialector.Name() == "postgres"
+	if locking {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+	}
+	if err := query.Find(&messages).Error; err != nil || !locking || len(messages) == 0 {
+		return messages, err
+	}
+
+	// an older message was skipped, another relay is sending it and these must wait for it
+	first := messages[0]
+	var older int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.OutboxMessage{}).
+		Where("published_at IS NULL AND (created_at < ? OR (created_at = ? AND id < ?))", first.CreatedAt, first.CreatedAt, first.ID).
+		Count(&older).Error
+	if err != nil {
+		return nil, err
+	}
+	if older > 0 {
+		return []domain.OutboxMessage{}, nil
+	}
+	return messages, nil
+}
+
+func (r *gormOutboxRepository) MarkPublished(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.OutboxMessage{}).
+		Where("id = ?", id).
+		Update("published_at", time.Now()).Error
+}
+
+func (r *gormOutboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, reason string) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.OutboxMessage{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":   gorm.Expr("attempts + 1"),
+			"last_error": reason,
+		}).Error
+}
@@ -0,0 +1,80 @@
+// internal/adapters/storage/outbox_repository_test.go
+
+package storage
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"gorm.io/gorm"
+)
+
+// oldest first and, on postgres, the messages claimed by a transaction are not given to
+// another one nor the ones after them
+func TestOutboxFindPending(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		// the postgres of the tests is kept, the messages of other runs are left out
+		if err := db.Model(&domain.OutboxMessage{}).Where("published_at IS NULL").Update("published_at", time.Now()).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		repo := NewGormOutboxRepository(db)
+		base := time.Now().UTC().Truncate(time.Second)
+		var ids []uuid.UUID
+		// saved newest first, the order comes from created_at
+		for i := 2; i >= 0; i-- {
+			message := domain.OutboxMessage{ID: uuid.New(), Event: "work_order_created", Payload: "{}", CreatedAt: base.Add(time.Duration(i) * time.Second)}
+			if err := repo.Create(ctx, message); err != nil {
+				t.Fatal(err)
+			}
+			ids = append([]uuid.UUID{message.ID}, ids...)
+		}
+		pendingIDs := func(messages []domain.OutboxMessage, err error) []uuid.UUID {
+			t.Helper()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []uuid.UUID{}
+			for _, message := range messages {
+				got = append(got, message.ID)
+			}
+			return got
+		}
+
+		if got := pendingIDs(repo.FindPending(ctx, 10)); !slices.Equal(got, ids) {
+			t.Fatalf("pending %v, want %v", got, ids)
+		}
+		if got := pendingIDs(repo.FindPending(ctx, 2)); !slices.Equal(got, ids[:2]) {
+			t.Fatalf("pending %v, want %v", got, ids[:2])
+		}
+
+		if db.Dialector.Name() != "postgres" {
+			return
+		}
+		tx := db.Begin()
+		t.Cleanup(func() { tx.Rollback() })
+		claimer := NewGormOutboxRepository(tx)
+		if got := pendingIDs(claimer.FindPending(ctx, 1)); !slices.Equal(got, ids[:1]) {
+			t.Fatalf("claimed %v, want %v", got, ids[:1])
+		}
+		// the rest would overtake the claimed one
+		if got := pendingIDs(repo.FindPending(ctx, 10)); len(got) != 0 {
+			t.Fatalf("pending %v while the oldest is claimed, want none", got)
+		}
+
+		if err := claimer.MarkPublished(ctx, ids[0]); err != nil {
+			t.Fatal(err)
+		}
+		if err := tx.Commit().Error; err != nil {
+			t.Fatal(err)
+		}
+		if got := pendingIDs(repo.FindPending(ctx, 10)); !slices.Equal(got, ids[1:]) {
+			t.Fatalf("pending %v after the commit, want %v", got, ids[1:])
+		}
+	})
+}
@@ -0,0 +1,62 @@
+// internal/adapters/storage/pagination.go
+
+package storage
+
+import (
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+	"gorm.io/gorm"
+)
+
+// fetches one page of the query into dest. Keyset pagination on (created_at, id), asks one row
+// more than the limit to know if there is a next page, the caller cuts it with pageOf. Preloads
+// go apart since Count can not preload
+func paginate(query *gorm.DB, pagination ports.Pagination, dest interface{}, preloads ...string) (int64, error) {
+	var total int64
+	// total ignores cursor and limit, new session so Count does not change query
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	query = query.Session(&gorm.Session{})
+	switch pagination.Sort {
+	case ports.SortDesc:
+		if pagination.Cursor != nil {
+			query = query.Where("(created_at, id) < (?, ?)", pagination.Cursor.CreatedAt, pagination.Cursor.ID)
+		}
+		query = query.Order("created_at DESC, id DESC")
+	default:
+		if pagination.Cursor != nil {
+			query = query.Where("(created_at, id) > (?, ?)", pagination.Cursor.CreatedAt, pagination.Cursor.ID)
+		}
+		query = query.Order("created_at ASC, id ASC")
+	}
+	if pagination.Limit > 0 {
+		query = query.Limit(pagination.Limit + 1)
+	}
+	for _, preload := range preloads {
+		query = query.Preload(preload)
+	}
+
+	return total, query.Find(dest).Error
+}
+
+// builds the page from the rows paginate fetched, key gives the cursor of a row
+func pageOf[T any](rows []T, pagination ports.Pagination, total int64, key func(T) ports.Cursor) ports.Page[T] {
+	page := ports.Page[T]{Items: rows, Total: total}
+	// the extra row exists, there is a next page starting after the last one returned
+	if pagination.Limit > 0 && len(rows) > pagination.Limit {
+		page.Items = rows[:pagination.Limit]
+		next := key(page.Items[len(page.Items)-1])
+		page.NextCursor = &next
+	}
+	return page
+}
+
+func customerCursor(customer domain.Customer) ports.Cursor {
+	return ports.Cursor{CreatedAt: customer.CreatedAt, ID: customer.ID}
+}
+
+func workOrderCursor(workOrder domain.WorkOrder) ports.Cursor {
+	return ports.Cursor{CreatedAt: workOrder.CreatedAt, ID: workOrder.ID}
+}
@@ -0,0 +1,30 @@
+// internal/adapters/storage/repository_test.go
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/krud3/prueba-tecnica/internal/adapters/repotest"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+	"gorm.io/gorm"
+)
+
+func TestRepositories(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		repotest.Run(t, func(t *testing.T) repotest.Repositories {
+			return repotest.Repositories{
+				Customers:  NewGormCustomerRepository(db),
+				WorkOrders: NewGormWorkOrderRepository(db),
+			}
+		})
+	})
+}
+
+func TestIdempotencyStore(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		repotest.RunIdempotencyStore(t, func(t *testing.T) ports.IdempotencyStore {
+			return NewGormIdempotencyStore(db)
+		})
+	})
+}
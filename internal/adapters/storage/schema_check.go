@@ -0,0 +1,175 @@
+// internal/adapters/storage/schema_check.go
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// kinds of SchemaDrift
+const (
+	DriftMissingTable  = "missing_table"
+	DriftMissingColumn = "missing_column"
+	DriftExtraColumn   = "extra_column"
+	DriftNullability   = "nullability"
+	DriftType          = "type"
+)
+
+// SchemaDrift is a difference between a gorm model and its table in the database
+type SchemaDrift struct {
+	Table  string
+	Column string
+	Kind   string
+	Detail string
+}
+
+func (d SchemaDrift) String() string {
+	if d.Column == "" {
+		return fmt.Sprintf("%s: %s, %s", d.Table, d.Kind, d.Detail)
+	}
+	return fmt.Sprintf("%s.%s: %s, %s", d.Table, d.Column, d.Kind, d.Detail)
+}
+
+// Models are the structs the repositories read and write, every one has its table in migrations/
+func Models() []any {
+	return []any{
+		&domain.Customer{},
+		&domain.CustomerServicePeriod{},
+		&domain.WorkOrder{},
+		&domain.WorkOrderChangeLog{},
+		&domain.WorkOrderStatusChange{},
+		&domain.OutboxMessage{},
+		&domain.IdempotencyKey{},
+	}
+}
+
+// database types each gorm data type can be stored in, types set with the type tag (uuid, jsonb,
+// work_order_status) must be the same in the table
+var compatibleTypes = map[schema.DataType][]string{
+	schema.Bool:   {"bool", "boolean"},
+	schema.Int:    {"int2", "int4", "int8", "smallint", "integer", "bigint"},
+	schema.Uint:   {"int2", "int4", "int8", "smallint", "integer", "bigint"},
+	schema.Float:  {"float4", "float8", "numeric", "real", "double precision"},
+	schema.String: {"varchar", "text", "bpchar", "character varying", "character"},
+	schema.Time:   {"timestamptz", "timestamp with time zone", "datetime"},
+	schema.Bytes:  {"bytea", "blob"},
+}
+
+// CheckSchema compares the models with the tables of the migrated database, nil means they
+// agree. Columns of the model missing in the table, columns of the table missing in the model,
+// a not null on only one side and types that can not hold the field are reported
+func CheckSchema(ctx context.Context, db *gorm.DB, models ...any) ([]SchemaDrift, error) {
+	db = db.WithContext(ctx)
+	sqlite := isSQLite(db)
+	var drifts []SchemaDrift
+
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, err
+		}
+		table := stmt.Schema.Table
+
+		if !db.Migrator().HasTable(model) {
+			drifts = append(drifts, SchemaDrift{Table: table, Kind: DriftMissingTable, Detail: "la tabla no existe"})
+			continue
+		}
+		columnTypes, err := db.Migrator().ColumnTypes(model)
+		if err != nil {
+			return nil, err
+		}
+		columns := make(map[string]gorm.ColumnType, len(columnTypes))
+		for _, column := range columnTypes {
+			columns[column.Name()] = column
+		}
+
+		fields := make(map[string]bool)
+		for _, field := range stmt.Schema.Fields {
+			// associations like WorkOrder.Customer have no column
+			if field.DBName == "" {
+				continue
+			}
+			fields[field.DBName] = true
+
+			column, ok := columns[field.DBName]
+			if !ok {
+				drifts = append(drifts, SchemaDrift{Table: table, Column: field.DBName, Kind: DriftMissingColumn, Detail: "el modelo tiene el campo " + field.Name + " pero la tabla no tiene la columna"})
+				continue
+			}
+			drifts = append(drifts, compareColumn(table, field, column, sqlite)...)
+		}
+
+		for _, column := range columnTypes {
+			if !fields[column.Name()] {
+				drifts = append(drifts, SchemaDrift{Table: table, Column: column.Name(), Kind: DriftExtraColumn, Detail: "la tabla tiene la columna pero el modelo no tiene el campo"})
+			}
+		}
+	}
+
+	sort.SliceStable(drifts, func(i, j int) bool {
+		if drifts[i].Table != drifts[j].Table {
+			return drifts[i].Table < drifts[j].Table
+		}
+		return drifts[i].Column < drifts[j].Column
+	})
+	return drifts, nil
+}
+
+func compareColumn(table string, field *schema.Field, column gorm.ColumnType, sqlite bool) []SchemaDrift {
+	var drifts []SchemaDrift
+	databaseType := strings.ToLower(column.DatabaseTypeName())
+
+	if nullable, ok := column.Nullable(); ok {
+		switch {
+		case (field.NotNull || field.PrimaryKey) && nullable:
+			drifts = append(drifts, SchemaDrift{Table: table, Column: field.DBName, Kind: DriftNullability, Detail: "el modelo dice not null pero la columna acepta NULL"})
+		case !nullable && canBeNil(field):
+			drifts = append(drifts, SchemaDrift{Table: table, Column: field.DBName, Kind: DriftNullability, Detail: "el campo " + field.Name + " puede ser nil pero la columna es NOT NULL"})
+		}
+	}
+
+	if !typeMatches(field.DataType, databaseType, sqlite) {
+		drifts = append(drifts, SchemaDrift{Table: table, Column: field.DBName, Kind: DriftType, Detail: fmt.Sprintf("el modelo usa %s pero la columna es %s", field.DataType, databaseType)})
+	}
+	return drifts
+}
+
+// pointers, slices and the Null* types of database/sql and gorm are saved as NULL
+func canBeNil(field *schema.Field) bool {
+	switch field.FieldType.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	}
+	name := field.FieldType.Name()
+	return strings.HasPrefix(name, "Null") || name == "DeletedAt"
+}
+
+func typeMatches(dataType schema.DataType, databaseType string, sqlite bool) bool {
+	compatible, ok := compatibleTypes[dataType]
+	if !ok && sqlite {
+		// sqlite has no uuid, jsonb nor enums, migrations/sqlite keeps them in TEXT
+		return databaseType == "text"
+	}
+	if !ok {
+		// type tag, ej. uuid or varchar(50), the size is not compared
+		expected := strings.ToLower(string(dataType))
+		if i := strings.Index(expected, "("); i >= 0 {
+			expected = expected[:i]
+		}
+		return strings.TrimSpace(expected) == databaseType
+	}
+	for _, name := range compatible {
+		if name == databaseType {
+			return true
+		}
+	}
+	return false
+}
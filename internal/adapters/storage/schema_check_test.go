@@ -0,0 +1,49 @@
+// internal/adapters/storage/schema_check_test.go
+
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// the migrations and the models must agree, a new field without its migration fails here
+func TestMigrationsMatchModels(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		drifts, err := CheckSchema(context.Background(), db, Models()...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, drift := range drifts {
+			t.Errorf("drift: %s", drift)
+		}
+	})
+}
+
+// customers with a field the table does not have
+type customerWithExtraField struct {
+	ID       uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Nickname string    `gorm:"not null"`
+}
+
+func (customerWithExtraField) TableName() string {
+	return "customers"
+}
+
+func TestCheckSchemaReportsDrift(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		drifts, err := CheckSchema(context.Background(), db, &customerWithExtraField{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, drift := range drifts {
+			if drift.Column == "nickname" && drift.Kind == DriftMissingColumn {
+				return
+			}
+		}
+		t.Fatalf("missing column nickname not reported, got %v", drifts)
+	})
+}
@@ -0,0 +1,63 @@
+// internal/adapters/storage/sqlite.go
+
+//go:build cgo
+
+package storage
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"github.com/krud3/prueba-tecnica/internal/config"
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// name of the sqlite driver that saves dates in UTC, see utcConn
+const sqliteDriverName = "sqlite3_utc"
+
+func init() {
+	sql.Register(sqliteDriverName, &utcDriver{})
+}
+
+func newSQLiteDialector(cfg config.DatabaseConfig) (gorm.Dialector, error) {
+	return sqlite.New(sqlite.Config{DriverName: sqliteDriverName, DSN: cfg.SQLiteDSN()}), nil
+}
+
+// RAISE(ABORT) of the triggers that emulate the exclusion constraint of postgres
+func isSQLiteTriggerError(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintTrigger
+}
+
+type utcDriver struct {
+	sqlite3.SQLiteDriver
+}
+
+func (d *utcDriver) Open(dsn string) (driver.Conn, error) {
+	conn, err := d.SQLiteDriver.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &utcConn{conn.(*sqlite3.SQLiteConn)}, nil
+}
+
+// sqlite saves dates as text with the zone they come with, in UTC they compare right as text
+type utcConn struct {
+	*sqlite3.SQLiteConn
+}
+
+func (c *utcConn) CheckNamedValue(value *driver.NamedValue) error {
+	converted, err := driver.DefaultParameterConverter.ConvertValue(value.Value)
+	if err != nil {
+		return err
+	}
+	if date, ok := converted.(time.Time); ok {
+		converted = date.UTC()
+	}
+	value.Value = converted
+	return nil
+}
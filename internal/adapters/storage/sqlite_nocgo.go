@@ -0,0 +1,18 @@
+// internal/adapters/storage/sqlite_nocgo.go
+
+//go:build !cgo
+
+package storage
+
+import (
+	"github.com/krud3/prueba-tecnica/internal/config"
+	"gorm.io/gorm"
+)
+
+func newSQLiteDialector(cfg config.DatabaseConfig) (gorm.Dialector, error) {
+	return nil, errSQLiteNeedsCgo
+}
+
+func isSQLiteTriggerError(err error) bool {
+	return false
+}
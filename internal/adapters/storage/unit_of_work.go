@@ -0,0 +1,33 @@
+// internal/adapters/storage/unit_of_work.go
+
+package storage
+
+import (
+	"context"
+
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+	"gorm.io/gorm"
+)
+
+type gormUnitOfWork struct {
+	db *gorm.DB
+}
+
+func NewGormUnitOfWork(db *gorm.DB) ports.UnitOfWork {
+	return &gormUnitOfWork{db: db}
+}
+
+func (u *gormUnitOfWork) Do(ctx context.Context, fn func(repos ports.TxRepositories) error) error {
+	// gorm commits when fn returns nil and rollbacks on error or panic
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// same repositories, but every query goes through tx
+		return fn(ports.TxRepositories{
+			Customers:     NewGormCustomerRepository(tx),
+			WorkOrders:    NewGormWorkOrderRepository(tx),
+			Outbox:        NewGormOutboxRepository(tx),
+			ChangeLogs:    NewGormWorkOrderChangeLogRepository(tx),
+			StatusChanges: NewGormWorkOrderStatusChangeRepository(tx),
+			Periods:       NewGormCustomerServicePeriodRepository(tx),
+		})
+	})
+}
@@ -0,0 +1,40 @@
+// internal/adapters/storage/workorder_change_log_repository.go
+
+package storage
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+	"gorm.io/gorm"
+)
+
+type gormWorkOrderChangeLogRepository struct {
+	db *gorm.DB
+}
+
+func NewGormWorkOrderChangeLogRepository(db *gorm.DB) ports.WorkOrderChangeLogRepository {
+	return &gormWorkOrderChangeLogRepository{db: db}
+}
+
+func (r *gormWorkOrderChangeLogRepository) Create(ctx context.Context, changeLog domain.WorkOrderChangeLog) error {
+	if changeLog.ID == uuid.Nil {
+		changeLog.ID = uuid.New()
+	}
+	// the work order must exist
+	return mapError(r.db.WithContext(ctx).Create(&changeLog).Error, domain.ErrWorkOrderNotFound)
+}
+
+func (r *gormWorkOrderChangeLogRepository) FindByWorkOrderID(ctx context.Context, workOrderID uuid.UUID) ([]domain.WorkOrderChangeLog, error) {
+	changeLogs := []domain.WorkOrderChangeLog{}
+
+	// oldest first, reads like a timeline
+	err := r.db.WithContext(ctx).
+		Where("work_order_id = ?", workOrderID).
+		Order("created_at, id").
+		Find(&changeLogs).Error
+
+	return changeLogs, err
+}
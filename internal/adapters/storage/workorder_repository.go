@@ -4,18 +4,23 @@ package storage
 
 import (
 	"context"
-	"errors"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/krud3/prueba-tecnica/internal/core/domain"
 	"github.com/krud3/prueba-tecnica/internal/core/ports"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var (
-	ErrNoWID = errors.New("no se encontró ID asociada al work order")
+	ErrNoWID = domain.NewValidationError("work_order_id_required", "no se encontró ID asociada al work order")
 )
 
+// escapes the wildcards of LIKE, backslash is the default escape character in postgres
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 type gormWorkOrderRepository struct {
 	db *gorm.DB
 }
@@ -32,7 +37,7 @@ func (r *gormWorkOrderRepository) Create(ctx context.Context, workOrder domain.W
 	result := r.db.WithContext(ctx).Create(&workOrder)
 
 	// if there is any error return it
-	return result.Error
+	return mapError(result.Error, domain.ErrWorkOrderNotFound)
 }
 
 func (r *gormWorkOrderRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.WorkOrder, error) {
@@ -41,18 +46,14 @@ func (r *gormWorkOrderRepository) FindByID(ctx context.Context, id uuid.UUID) (*
 	// preload of customer since condition 9 especifys it, due workOrder pointer SELECT assigns workOrder finded to var workOrder
 	result := r.db.WithContext(ctx).Preload("Customer").First(&workOrder, "id = ?", id)
 	if result.Error != nil {
-		// if not found nil nil
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, nil
-		}
-		// if error
-		return nil, result.Error
+		// not found or any other error
+		return nil, mapError(result.Error, domain.ErrWorkOrderNotFound)
 	}
 	// founded
 	return &workOrder, nil
 }
 
-func (r *gormWorkOrderRepository) FindByFilter(ctx context.Context, filters ports.WorkOrderFilters) ([]domain.WorkOrder, error) {
+func (r *gormWorkOrderRepository) FindByFilter(ctx context.Context, filters ports.WorkOrderFilters) (ports.Page[domain.WorkOrder], error) {
 	// stores workOrders finded if any
 	var workOrders []domain.WorkOrder
 	// specify wich talbe gorm is working on
@@ -68,34 +69,145 @@ func (r *gormWorkOrderRepository) FindByFilter(ctx context.Context, filters port
 	if filters.Status != nil {
 		query = query.Where("status = ?", *filters.Status)
 	}
+	if filters.CustomerID != nil {
+		query = query.Where("customer_id = ?", *filters.CustomerID)
+	}
+	if filters.Type != nil {
+		query = query.Where("type = ?", *filters.Type)
+	}
+	if filters.Search != "" {
+		// on postgres uses the trigram index on description, % and _ are escaped to be searched literally
+		query = query.Where(containsText(r.db, "description"), "%"+likeEscaper.Replace(filters.Search)+"%")
+	}
+	if filters.CreatedSince != nil {
+		query = query.Where("created_at >= ?", *filters.CreatedSince)
+	}
+	if filters.CreatedUntil != nil {
+		query = query.Where("created_at <= ?", *filters.CreatedUntil)
+	}
 
-	// Preload customer and storage results in workOrders
-	err := query.Preload("Customer").Find(&workOrders).Error
+	// Preload customer and storage one page of results in workOrders
+	total, err := paginate(query, filters.Pagination, &workOrders, "Customer")
 	if err != nil {
-		return nil, err
+		return ports.Page[domain.WorkOrder]{}, mapError(err, domain.ErrWorkOrderNotFound)
 	}
 
-	return workOrders, nil
+	return pageOf(workOrders, filters.Pagination, total, workOrderCursor), nil
 }
 
-func (r *gormWorkOrderRepository) FindByCustomerID(ctx context.Context, customerID uuid.UUID) ([]domain.WorkOrder, error) {
+func (r *gormWorkOrderRepository) FindByCustomerID(ctx context.Context, customerID uuid.UUID, pagination ports.Pagination) (ports.Page[domain.WorkOrder], error) {
 	// stores workOrders if any
 	var workOrders []domain.WorkOrder
 
-	// where filter Preloads Customer storage in workOrders
-	err := r.db.WithContext(ctx).Where("customer_id = ?", customerID).Preload("Customer").Find(&workOrders).Error
+	// where filter Preloads Customer storage one page in workOrders
+	query := r.db.WithContext(ctx).Model(&domain.WorkOrder{}).Where("customer_id = ?", customerID)
+	total, err := paginate(query, pagination, &workOrders, "Customer")
+	if err != nil {
+		return ports.Page[domain.WorkOrder]{}, mapError(err, domain.ErrWorkOrderNotFound)
+	}
 
-	return workOrders, err
+	return pageOf(workOrders, pagination, total, workOrderCursor), nil
 }
 
 func (r *gormWorkOrderRepository) Update(ctx context.Context, workOrder domain.WorkOrder) error {
 	// if no id given error
 	if workOrder.ID == uuid.Nil {
 		return ErrNoWID
-	} else {
-		// save update value in db
-		result := r.db.WithContext(ctx).Save(&workOrder)
-		return result.Error
 	}
 
+	// only the row with the version that was read is updated, optimistic concurrency
+	version := workOrder.Version
+	workOrder.Version++
+	result := r.db.WithContext(ctx).
+		Model(&workOrder).
+		Select("*").
+		Omit("CreatedAt", clause.Associations).
+		Where("version = ?", version).
+		Updates(&workOrder)
+	if result.Error != nil {
+		return mapError(result.Error, domain.ErrWorkOrderNotFound)
+	}
+	// changed by someone else since it was read
+	if result.RowsAffected == 0 {
+		return domain.ErrVersionConflict
+	}
+	return nil
+}
+
+func (r *gormWorkOrderRepository) FindOverlapping(ctx context.Context, customerID uuid.UUID, begin, end time.Time, excludeID uuid.UUID) ([]domain.WorkOrder, error) {
+	var workOrders []domain.WorkOrder
+
+	result := r.db.WithContext(ctx).
+		Where("customer_id = ? AND status = ? AND id <> ?", customerID, domain.StatusNew, excludeID).
+		Where(overlaps(r.db, "planned_date_begin", "planned_date_end", "?", "?"), begin, end).
+		Order("planned_date_begin").
+		Find(&workOrders)
+	if result.Error != nil {
+		return nil, mapError(result.Error, domain.ErrWorkOrderNotFound)
+	}
+	return workOrders, nil
+}
+
+func (r *gormWorkOrderRepository) FindConflicts(ctx context.Context, since, until time.Time, customerID *uuid.UUID) ([]ports.WorkOrderConflict, error) {
+	// ids of each pair, a.id < b.id so a pair is not listed twice
+	var pairs []struct {
+		WorkOrderID     uuid.UUID
+		ConflictsWithID uuid.UUID
+	}
+	query := r.db.WithContext(ctx).
+		Table("work_orders AS a").
+		Select("a.id AS work_order_id, b.id AS conflicts_with_id").
+		Joins("JOIN work_orders AS b ON b.customer_id = a.customer_id AND a.id < b.id AND "+overlaps(r.db, "a.planned_date_begin", "a.planned_date_end", "b.planned_date_begin", "b.planned_date_end")).
+		Where("a.status = ? AND b.status = ?", domain.StatusNew, domain.StatusNew).
+		Where(overlaps(r.db, "a.planned_date_begin", "a.planned_date_end", "?", "?"), since, until).
+		Where(overlaps(r.db, "b.planned_date_begin", "b.planned_date_end", "?", "?"), since, until)
+	if customerID != nil {
+		query = query.Where("a.customer_id = ?", *customerID)
+	}
+	if err := query.Order("a.planned_date_begin, a.id, b.id").Scan(&pairs).Error; err != nil {
+		return nil, mapError(err, domain.ErrWorkOrderNotFound)
+	}
+	if len(pairs) == 0 {
+		return []ports.WorkOrderConflict{}, nil
+	}
+
+	// every order of the pairs in one query, with its customer
+	ids := make([]uuid.UUID, 0, len(pairs)*2)
+	for _, pair := range pairs {
+		ids = append(ids, pair.WorkOrderID, pair.ConflictsWithID)
+	}
+	var workOrders []domain.WorkOrder
+	if err := r.db.WithContext(ctx).Preload("Customer").Where("id IN ?", ids).Find(&workOrders).Error; err != nil {
+		return nil, mapError(err, domain.ErrWorkOrderNotFound)
+	}
+	byID := make(map[uuid.UUID]domain.WorkOrder, len(workOrders))
+	for _, workOrder := range workOrders {
+		byID[workOrder.ID] = workOrder
+	}
+
+	conflicts := make([]ports.WorkOrderConflict, 0, len(pairs))
+	for _, pair := range pairs {
+		conflicts = append(conflicts, ports.WorkOrderConflict{
+			WorkOrder:     byID[pair.WorkOrderID],
+			ConflictsWith: byID[pair.ConflictsWithID],
+		})
+	}
+	return conflicts, nil
+}
+
+// condition for [beginA, endA) overlapping [beginB, endB), columns or ? in the same order. On
+// postgres they are the ranges of the exclusion constraint, so its gist index is used
+func overlaps(db *gorm.DB, beginA, endA, beginB, endB string) string {
+	if isSQLite(db) {
+		return beginB + " < " + endA + " AND " + beginA + " < " + endB
+	}
+	return "tstzrange(" + beginA + ", " + endA + ") && tstzrange(" + beginB + ", " + endB + ")"
+}
+
+// case insensitive LIKE on column, sqlite has no ILIKE and no default escape character
+func containsText(db *gorm.DB, column string) string {
+	if isSQLite(db) {
+		return column + ` LIKE ? ESCAPE '\'`
+	}
+	return column + " ILIKE ?"
 }
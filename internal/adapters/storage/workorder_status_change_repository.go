@@ -0,0 +1,40 @@
+// internal/adapters/storage/workorder_status_change_repository.go
+
+package storage
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+	"gorm.io/gorm"
+)
+
+type gormWorkOrderStatusChangeRepository struct {
+	db *gorm.DB
+}
+
+func NewGormWorkOrderStatusChangeRepository(db *gorm.DB) ports.WorkOrderStatusChangeRepository {
+	return &gormWorkOrderStatusChangeRepository{db: db}
+}
+
+func (r *gormWorkOrderStatusChangeRepository) Create(ctx context.Context, change domain.WorkOrderStatusChange) error {
+	if change.ID == uuid.Nil {
+		change.ID = uuid.New()
+	}
+	// the work order must exist
+	return mapError(r.db.WithContext(ctx).Create(&change).Error, domain.ErrWorkOrderNotFound)
+}
+
+func (r *gormWorkOrderStatusChangeRepository) FindByWorkOrderID(ctx context.Context, workOrderID uuid.UUID) ([]domain.WorkOrderStatusChange, error) {
+	changes := []domain.WorkOrderStatusChange{}
+
+	// oldest first, reads like a timeline
+	err := r.db.WithContext(ctx).
+		Where("work_order_id = ?", workOrderID).
+		Order("created_at, id").
+		Find(&changes).Error
+
+	return changes, err
+}
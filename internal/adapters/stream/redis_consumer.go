@@ -0,0 +1,261 @@
+// internal/adapters/stream/redis_consumer.go
+
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+)
+
+type ConsumerConfig struct {
+	Stream string
+	Group  string
+	// name of this worker inside the group, must be unique per process
+	Consumer string
+	// entries that fail MaxAttempts times are moved here
+	DeadLetterStream string
+	// entries pending longer than this in any consumer are taken by this one
+	ClaimIdle   time.Duration
+	MaxAttempts int64
+	// how long XREADGROUP waits for new entries, also the max time Run takes to stop
+	Block     time.Duration
+	BatchSize int64
+}
+
+// RedisStreamConsumer reads the stream as part of a consumer group and gives each entry to the
+// handler of its event. Entries are acked only when the handler succeeds, failed ones stay
+// pending and are claimed again after ClaimIdle
+type RedisStreamConsumer struct {
+	client   *redis.Client
+	cfg      ConsumerConfig
+	handlers map[string]ports.EventHandler
+}
+
+func NewRedisStreamConsumer(client *redis.Client, cfg ConsumerConfig) *RedisStreamConsumer {
+	return &RedisStreamConsumer{
+		client:   client,
+		cfg:      cfg,
+		handlers: make(map[string]ports.EventHandler),
+	}
+}
+
+// Register sets the handler of an event, entries of events without handler are acked and skipped
+func (c *RedisStreamConsumer) Register(event string, handler ports.EventHandler) {
+	c.handlers[event] = handler
+}
+
+// Run consumes until ctx is cancelled
+func (c *RedisStreamConsumer) Run(ctx context.Context) error {
+	if err := c.createGroup(ctx); err != nil {
+		return err
+	}
+
+	// claim what other consumers left behind before reading new entries
+	nextClaim := time.Now()
+	for ctx.Err() == nil {
+		if !time.Now().Before(nextClaim) {
+			if err := c.reclaim(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("Error reclamando eventos pendientes: %v", err)
+			}
+			nextClaim = time.Now().Add(c.cfg.ClaimIdle)
+		}
+
+		streams, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    c.cfg.Group,
+			Consumer: c.cfg.Consumer,
+			Streams:  []string{c.cfg.Stream, ">"},
+			Count:    c.cfg.BatchSize,
+			Block:    c.cfg.Block,
+		}).Result()
+		switch {
+		// nothing new during Block
+		case errors.Is(err, redis.Nil):
+			continue
+		case err != nil:
+			// stopped while reading
+			if ctx.Err() != nil {
+				return nil
+			}
+			log.Printf("Error leyendo el stream %s: %v", c.cfg.Stream, err)
+			// wait a bit, redis could be restarting
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		for _, s := range streams {
+			for _, message := range s.Messages {
+				c.process(ctx, message)
+			}
+		}
+	}
+	return nil
+}
+
+// creates the group and the stream if they do not exist, starts from the first entry
+func (c *RedisStreamConsumer) createGroup(ctx context.Context) error {
+	err := c.client.XGroupCreateMkStream(ctx, c.cfg.Stream, c.cfg.Group, "0").Err()
+	// already created by this or another worker
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+	return nil
+}
+
+// takes every entry idle for more than ClaimIdle and processes it again
+func (c *RedisStreamConsumer) reclaim(ctx context.Context) error {
+	start := "0-0"
+	for {
+		// XAutoClaim of go-redis v8 expects the reply of redis 6.2, redis 7 adds a third
+		// element so the reply is read by hand
+		reply, err := c.client.Do(ctx, "XAUTOCLAIM", c.cfg.Stream, c.cfg.Group, c.cfg.Consumer,
+			c.cfg.ClaimIdle.Milliseconds(), start, "COUNT", c.cfg.BatchSize).Slice()
+		if err != nil {
+			return err
+		}
+		next, messages, err := parseAutoClaim(reply)
+		if err != nil {
+			return err
+		}
+
+		for _, message := range messages {
+			c.process(ctx, message)
+		}
+		// the whole pending list was scanned
+		if next == "0-0" {
+			return nil
+		}
+		start = next
+	}
+}
+
+// runs the handler, acks on success and moves the entry to the dead letter stream when it
+// already failed MaxAttempts times
+func (c *RedisStreamConsumer) process(ctx context.Context, message redis.XMessage) {
+	err := c.dispatch(ctx, message)
+	if err == nil {
+		if err := c.client.XAck(ctx, c.cfg.Stream, c.cfg.Group, message.ID).Err(); err != nil {
+			log.Printf("Error confirmando el evento %s: %v", message.ID, err)
+		}
+		return
+	}
+	log.Printf("Error procesando el evento %s: %v", message.ID, err)
+
+	// redis counts every delivery, the first read included
+	attempts, errA := c.attempts(ctx, message.ID)
+	if errA != nil {
+		log.Printf("Error consultando los intentos del evento %s: %v", message.ID, errA)
+		return
+	}
+	if attempts < c.cfg.MaxAttempts {
+		// stays pending, reclaim picks it again
+		return
+	}
+
+	if err := c.deadLetter(ctx, message, attempts, err); err != nil {
+		log.Printf("Error moviendo el evento %s al stream %s: %v", message.ID, c.cfg.DeadLetterStream, err)
+		return
+	}
+	log.Printf("Evento %s movido a %s después de %d intentos", message.ID, c.cfg.DeadLetterStream, attempts)
+}
+
+// gives every field of the entry to its handler, a panic counts as an error so a bad payload
+// can not stop the worker
+func (c *RedisStreamConsumer) dispatch(ctx context.Context, message redis.XMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic en el handler: %v", r)
+		}
+	}()
+
+	// the publisher sends one field per entry, the event with its json
+	for event, value := range message.Values {
+		handler, ok := c.handlers[event]
+		if !ok {
+			log.Printf("Evento %s sin handler, se ignora", event)
+			continue
+		}
+		payload, _ := value.(string)
+		if err := handler.Handle(ctx, event, []byte(payload)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *RedisStreamConsumer) attempts(ctx context.Context, id string) (int64, error) {
+	pending, err := c.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: c.cfg.Stream,
+		Group:  c.cfg.Group,
+		Start:  id,
+		End:    id,
+		Count:  1,
+	}).Result()
+	if err != nil {
+		return 0, err
+	}
+	// not pending anymore, another consumer took care of it
+	if len(pending) == 0 {
+		return 0, nil
+	}
+	return pending[0].RetryCount, nil
+}
+
+// copies the entry to the dead letter stream and acks it in the same transaction
+func (c *RedisStreamConsumer) deadLetter(ctx context.Context, message redis.XMessage, attempts int64, cause error) error {
+	values := make(map[string]interface{}, len(message.Values)+3)
+	for field, value := range message.Values {
+		values[field] = value
+	}
+	values["original_id"] = message.ID
+	values["attempts"] = attempts
+	values["error"] = cause.Error()
+
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAdd(ctx, &redis.XAddArgs{Stream: c.cfg.DeadLetterStream, Values: values})
+		pipe.XAck(ctx, c.cfg.Stream, c.cfg.Group, message.ID)
+		return nil
+	})
+	return err
+}
+
+// reads [next start, [[id, [field, value, ...]], ...], (deleted ids)]
+func parseAutoClaim(reply []interface{}) (string, []redis.XMessage, error) {
+	if len(reply) < 2 {
+		return "", nil, fmt.Errorf("respuesta de XAUTOCLAIM inesperada: %v", reply)
+	}
+	// an empty next would not stop reclaim, it is an error like a short reply
+	next, nextOK := reply[0].(string)
+	entries, entriesOK := reply[1].([]interface{})
+	if !nextOK || next == "" || !entriesOK {
+		return "", nil, fmt.Errorf("respuesta de XAUTOCLAIM inesperada: %v", reply)
+	}
+
+	messages := make([]redis.XMessage, 0, len(entries))
+	for _, entry := range entries {
+		// redis 6.2 returns nil for entries deleted from the stream
+		pair, ok := entry.([]interface{})
+		if !ok || len(pair) != 2 {
+			continue
+		}
+		id, _ := pair[0].(string)
+		fields, _ := pair[1].([]interface{})
+
+		values := make(map[string]interface{}, len(fields)/2)
+		for i := 0; i+1 < len(fields); i += 2 {
+			field, _ := fields[i].(string)
+			values[field] = fields[i+1]
+		}
+		messages = append(messages, redis.XMessage{ID: id, Values: values})
+	}
+	return next, messages, nil
+}
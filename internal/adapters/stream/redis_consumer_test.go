@@ -0,0 +1,197 @@
+// internal/adapters/stream/redis_consumer_test.go
+
+package stream
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func TestParseAutoClaim(t *testing.T) {
+	entry := []interface{}{"1-0", []interface{}{"work_order_created", `{"id":1}`}}
+
+	tests := []struct {
+		name     string
+		reply    []interface{}
+		wantNext string
+		want     []redis.XMessage
+		wantErr  bool
+	}{
+		{
+			name:     "redis 7 with deleted ids",
+			reply:    []interface{}{"2-0", []interface{}{entry}, []interface{}{"0-5"}},
+			wantNext: "2-0",
+			want:     []redis.XMessage{{ID: "1-0", Values: map[string]interface{}{"work_order_created": `{"id":1}`}}},
+		},
+		{
+			name:     "redis 6.2 end of the pending list",
+			reply:    []interface{}{"0-0", []interface{}{entry}},
+			wantNext: "0-0",
+			want:     []redis.XMessage{{ID: "1-0", Values: map[string]interface{}{"work_order_created": `{"id":1}`}}},
+		},
+		{
+			name:     "nothing to claim",
+			reply:    []interface{}{"0-0", []interface{}{}, []interface{}{}},
+			wantNext: "0-0",
+			want:     []redis.XMessage{},
+		},
+		{
+			// redis 6.2 gives nil for entries deleted from the stream
+			name:     "deleted entries are skipped",
+			reply:    []interface{}{"0-0", []interface{}{nil, entry, []interface{}{"3-0"}}},
+			wantNext: "0-0",
+			want:     []redis.XMessage{{ID: "1-0", Values: map[string]interface{}{"work_order_created": `{"id":1}`}}},
+		},
+		{
+			name:     "field without value",
+			reply:    []interface{}{"0-0", []interface{}{[]interface{}{"1-0", []interface{}{"a", "1", "b"}}}},
+			wantNext: "0-0",
+			want:     []redis.XMessage{{ID: "1-0", Values: map[string]interface{}{"a": "1"}}},
+		},
+		{name: "empty reply", reply: []interface{}{}, wantErr: true},
+		{name: "only the next start", reply: []interface{}{"0-0"}, wantErr: true},
+		{name: "next is not a string", reply: []interface{}{int64(0), []interface{}{}}, wantErr: true},
+		{name: "empty next", reply: []interface{}{"", []interface{}{}}, wantErr: true},
+		{name: "entries are not a list", reply: []interface{}{"0-0", "1-0"}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			next, messages, err := parseAutoClaim(tc.reply)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("parsed %q %v, want an error", next, messages)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if next != tc.wantNext || !reflect.DeepEqual(messages, tc.want) {
+				t.Fatalf("got %q %v, want %q %v", next, messages, tc.wantNext, tc.want)
+			}
+		})
+	}
+}
+
+// fails the first failures calls, panics instead when panics is set
+type flakyHandler struct {
+	failures int
+	panics   bool
+	calls    int
+}
+
+func (h *flakyHandler) Handle(ctx context.Context, event string, payload []byte) error {
+	h.calls++
+	if h.calls > h.failures {
+		return nil
+	}
+	if h.panics {
+		panic("payload inválido")
+	}
+	return errors.New("base de datos caída")
+}
+
+// an entry read once and claimed again each time it is idle, until it is acked or moved to the
+// dead letter stream after MaxAttempts deliveries
+func TestRedisStreamConsumerRetriesThenDeadLetters(t *testing.T) {
+	const maxAttempts = 3
+	cfg := ConsumerConfig{
+		Stream:           "work_orders_stream",
+		Group:            "work_orders_workers",
+		Consumer:         "worker-1",
+		DeadLetterStream: "work_orders_stream_dead",
+		ClaimIdle:        30 * time.Second,
+		MaxAttempts:      maxAttempts,
+		BatchSize:        10,
+	}
+
+	tests := []struct {
+		name      string
+		handler   *flakyHandler
+		wantCalls int
+		wantDead  bool
+	}{
+		{name: "first try", handler: &flakyHandler{}, wantCalls: 1},
+		{name: "retried until it works", handler: &flakyHandler{failures: 2}, wantCalls: 3},
+		{name: "always fails", handler: &flakyHandler{failures: 10}, wantCalls: maxAttempts, wantDead: true},
+		{name: "panics", handler: &flakyHandler{failures: 10, panics: true}, wantCalls: maxAttempts, wantDead: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			server := miniredis.RunT(t)
+			now := time.Now()
+			server.SetTime(now)
+			client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+			t.Cleanup(func() { client.Close() })
+
+			consumer := NewRedisStreamConsumer(client, cfg)
+			consumer.Register("work_order_created", tc.handler)
+			if err := consumer.createGroup(ctx); err != nil {
+				t.Fatal(err)
+			}
+			id, err := client.XAdd(ctx, &redis.XAddArgs{Stream: cfg.Stream, Values: map[string]interface{}{"work_order_created": `{"id":1}`}}).Result()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// first delivery, like Run
+			streams, err := client.XReadGroup(ctx, &redis.XReadGroupArgs{Group: cfg.Group, Consumer: cfg.Consumer, Streams: []string{cfg.Stream, ">"}, Count: 1}).Result()
+			if err != nil {
+				t.Fatal(err)
+			}
+			consumer.process(ctx, streams[0].Messages[0])
+
+			// not idle long enough, nothing is claimed
+			if err := consumer.reclaim(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if tc.handler.calls != 1 {
+				t.Fatalf("%d calls before ClaimIdle, want 1", tc.handler.calls)
+			}
+			// more claims than attempts, the entry must be gone before
+			for i := 0; i < maxAttempts+1; i++ {
+				now = now.Add(cfg.ClaimIdle)
+				server.SetTime(now)
+				if err := consumer.reclaim(ctx); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if tc.handler.calls != tc.wantCalls {
+				t.Fatalf("%d calls, want %d", tc.handler.calls, tc.wantCalls)
+			}
+			pending, err := client.XPending(ctx, cfg.Stream, cfg.Group).Result()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pending.Count != 0 {
+				t.Fatalf("%d entries still pending", pending.Count)
+			}
+
+			dead, err := client.XRange(ctx, cfg.DeadLetterStream, "-", "+").Result()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tc.wantDead {
+				if len(dead) != 0 {
+					t.Fatalf("%d entries in the dead letter stream, want none", len(dead))
+				}
+				return
+			}
+			if len(dead) != 1 {
+				t.Fatalf("%d entries in the dead letter stream, want 1", len(dead))
+			}
+			values := dead[0].Values
+			if values["original_id"] != id || values["attempts"] != "3" || values["error"] == "" || values["work_order_created"] != `{"id":1}` {
+				t.Fatalf("dead letter %v, want the entry %s with 3 attempts and the error", values, id)
+			}
+		})
+	}
+}
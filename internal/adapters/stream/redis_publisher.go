@@ -0,0 +1,36 @@
+// internal/adapters/stream/redis_publisher.go
+
+package stream
+
+import (
+	"context"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+)
+
+type redisStreamPublisher struct {
+	client *redis.Client
+	// stream of each event, events not listed go to defaultStream
+	streams       map[string]string
+	defaultStream string
+}
+
+func NewRedisStreamPublisher(client *redis.Client, defaultStream string, streams map[string]string) ports.EventPublisher {
+	return &redisStreamPublisher{client: client, streams: streams, defaultStream: defaultStream}
+}
+
+func (p *redisStreamPublisher) Publish(ctx context.Context, event string, payload []byte) error {
+	stream, ok := p.streams[event]
+	if !ok {
+		stream = p.defaultStream
+	}
+
+	// one entry per event, the field name is the event and the value its json
+	return p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		Values: map[string]interface{}{
+			event: string(payload),
+		},
+	}).Err()
+}
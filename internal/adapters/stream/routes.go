@@ -0,0 +1,34 @@
+// internal/adapters/stream/routes.go
+
+package stream
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/krud3/prueba-tecnica/internal/core/events"
+)
+
+// ParseRoutes reads the stream of each event from "event=stream,event=stream", ej.
+// "customer_created=customers_stream,customer_activated=customers_stream"
+func ParseRoutes(value string) (map[string]string, error) {
+	routes := make(map[string]string)
+	if strings.TrimSpace(value) == "" {
+		return routes, nil
+	}
+
+	for _, route := range strings.Split(value, ",") {
+		event, streamName, ok := strings.Cut(strings.TrimSpace(route), "=")
+		event, streamName = strings.TrimSpace(event), strings.TrimSpace(streamName)
+		if !ok || event == "" || streamName == "" {
+			return nil, fmt.Errorf("ruta de stream inválida: %q, debe ser evento=stream", route)
+		}
+		// a typo would send the event to the default stream without notice
+		if !slices.Contains(events.Types(), event) {
+			return nil, fmt.Errorf("evento desconocido en la ruta %q, debe ser uno de: %s", route, strings.Join(events.Types(), ", "))
+		}
+		routes[event] = streamName
+	}
+	return routes, nil
+}
@@ -0,0 +1,377 @@
+// internal/config/config.go
+
+// Package config loads the configuration of the api and the worker. Values are read in this
+// order, each one replaces the previous: defaults, the yaml file in CONFIG_FILE, the json rules
+// file in RULES_FILE, the .env and the environment
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	// port the api listens on
+	Port        string            `yaml:"port"`
+	CORS        CORSConfig        `yaml:"cors"`
+	Storage     StorageConfig     `yaml:"storage"`
+	Database    DatabaseConfig    `yaml:"database"`
+	Redis       RedisConfig       `yaml:"redis"`
+	Events      EventsConfig      `yaml:"events"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	// scheduling rules of work orders, cmd/api builds services.Policy with them
+	Rules  RulesConfig  `yaml:"rules"`
+	Worker WorkerConfig `yaml:"worker"`
+}
+
+type CORSConfig struct {
+	// empty allows every origin
+	AllowedOrigin string `yaml:"allowed_origin"`
+}
+
+type StorageConfig struct {
+	// postgres, sqlite or memory
+	Driver string `yaml:"driver"`
+}
+
+type DatabaseConfig struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"ssl_mode"`
+	// file of sqlite or :memory:, the other fields are for postgres
+	Path string `yaml:"path"`
+	// applies the pending migrations when the api starts
+	MigrateOnStart bool `yaml:"migrate_on_start"`
+}
+
+type RedisConfig struct {
+	Addr string `yaml:"addr"`
+}
+
+type EventsConfig struct {
+	// redis or memory
+	Publisher string `yaml:"publisher"`
+	// default stream and event=stream pairs separated by comma, see stream.ParseRoutes
+	Stream  string `yaml:"stream"`
+	Streams string `yaml:"streams"`
+}
+
+type IdempotencyConfig struct {
+	// postgres, redis or memory, empty follows the storage driver
+	Store string `yaml:"store"`
+	// how long a stored response is replayed
+	TTL time.Duration `yaml:"ttl"`
+	// how long the first request holds the key, after that a retry can run it again. Must be
+	// longer than the slowest handler
+	LockTimeout time.Duration `yaml:"lock_timeout"`
+}
+
+// RulesConfig are the rules as written, durations like 2h or 30m and working hours like
+// 08:00-18:00. Empty fields keep the default of services.DefaultPolicy. The json keys are the
+// ones of RULES_FILE, see rules.example.json
+type RulesConfig struct {
+	MaxDuration  string `yaml:"max_duration" json:"max_duration"`
+	MinLeadTime  string `yaml:"min_lead_time" json:"min_lead_time"`
+	WorkingHours string `yaml:"working_hours" json:"working_hours"`
+	// IANA name of the zone of the working hours, UTC if empty
+	Timezone string `yaml:"timezone" json:"timezone"`
+}
+
+type WorkerConfig struct {
+	// name in the consumer group, the hostname by default so a restarted worker keeps its pending entries
+	Consumer         string        `yaml:"consumer"`
+	Stream           string        `yaml:"stream"`
+	Group            string        `yaml:"group"`
+	DeadLetterStream string        `yaml:"dead_letter_stream"`
+	ClaimIdle        time.Duration `yaml:"claim_idle"`
+	MaxAttempts      int64         `yaml:"max_attempts"`
+}
+
+// Default has the values used when nothing else sets them
+func Default() Config {
+	return Config{
+		Port:     "3000",
+		Storage:  StorageConfig{Driver: "postgres"},
+		Database: DatabaseConfig{SSLMode: "disable", Path: "prueba-tecnica.db"},
+		Events: EventsConfig{
+			Publisher: "redis",
+			Stream:    "work_orders_stream",
+		},
+		Idempotency: IdempotencyConfig{TTL: 24 * time.Hour, LockTimeout: 30 * time.Second},
+		Worker: WorkerConfig{
+			Stream:      "work_orders_stream",
+			Group:       "work_orders_workers",
+			ClaimIdle:   30 * time.Second,
+			MaxAttempts: 5,
+		},
+	}
+}
+
+// Load reads the configuration, the errors of every invalid value are returned together.
+// Missing values are checked by ValidateAPI, ValidateWorker and ValidateMigrate since each one
+// needs different ones
+func Load() (Config, error) {
+	cfg := Default()
+
+	// the .env is optional, the environment can have everything
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return cfg, fmt.Errorf(".env: %w", err)
+	}
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, err
+		}
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	// only the rules, the keys it has replace the ones of the rules section of the yaml
+	if path := os.Getenv("RULES_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, err
+		}
+		if err := json.Unmarshal(data, &cfg.Rules); err != nil {
+			return cfg, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	env := envReader{}
+	env.str("PORT", &cfg.Port)
+	env.str("CORS_ALLOWED_ORIGIN", &cfg.CORS.AllowedOrigin)
+	env.str("STORAGE_DRIVER", &cfg.Storage.Driver)
+	env.str("DB_HOST", &cfg.Database.Host)
+	env.str("DB_PORT", &cfg.Database.Port)
+	env.str("DB_USER", &cfg.Database.User)
+	env.str("DB_PASSWORD", &cfg.Database.Password)
+	env.str("DB_NAME", &cfg.Database.Name)
+	env.str("DB_SSLMODE", &cfg.Database.SSLMode)
+	env.str("DB_PATH", &cfg.Database.Path)
+	env.boolean("DB_MIGRATE_ON_START", &cfg.Database.MigrateOnStart)
+	env.str("REDIS_ADDR", &cfg.Redis.Addr)
+	env.str("EVENT_PUBLISHER", &cfg.Events.Publisher)
+	env.str("EVENT_STREAM", &cfg.Events.Stream)
+	env.str("EVENT_STREAMS", &cfg.Events.Streams)
+	env.str("IDEMPOTENCY_STORE", &cfg.Idempotency.Store)
+	env.duration("IDEMPOTENCY_TTL", &cfg.Idempotency.TTL)
+	env.duration("IDEMPOTENCY_LOCK_TIMEOUT", &cfg.Idempotency.LockTimeout)
+	env.str("RULES_MAX_DURATION", &cfg.Rules.MaxDuration)
+	env.str("RULES_MIN_LEAD_TIME", &cfg.Rules.MinLeadTime)
+	env.str("RULES_WORKING_HOURS", &cfg.Rules.WorkingHours)
+	env.str("RULES_TIMEZONE", &cfg.Rules.Timezone)
+	env.str("WORKER_CONSUMER", &cfg.Worker.Consumer)
+	env.str("WORKER_STREAM", &cfg.Worker.Stream)
+	env.str("WORKER_GROUP", &cfg.Worker.Group)
+	env.str("WORKER_DEAD_LETTER_STREAM", &cfg.Worker.DeadLetterStream)
+	env.duration("WORKER_CLAIM_IDLE", &cfg.Worker.ClaimIdle)
+	env.integer("WORKER_MAX_ATTEMPTS", &cfg.Worker.MaxAttempts)
+	if err := errors.Join(env.errs...); err != nil {
+		return cfg, err
+	}
+
+	if cfg.Worker.Consumer == "" {
+		// left empty if it fails, ValidateWorker reports it
+		cfg.Worker.Consumer, _ = os.Hostname()
+	}
+	// each stream has its own dead letter stream
+	if cfg.Worker.DeadLetterStream == "" {
+		cfg.Worker.DeadLetterStream = cfg.Worker.Stream + "_dead"
+	}
+	return cfg, nil
+}
+
+// ValidateAPI checks everything cmd/api needs, the database only with postgres and redis only
+// when something uses it. The rules are checked by services when the policy is built
+func (c Config) ValidateAPI() error {
+	var errs []error
+
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("PORT inválido: %q, debe ser un número entre 1 y 65535", c.Port))
+	}
+
+	if c.Storage.Driver != "memory" {
+		errs = append(errs, c.validateDatabase()...)
+	}
+
+	switch c.Events.Publisher {
+	case "redis", "memory":
+	default:
+		errs = append(errs, fmt.Errorf("EVENT_PUBLISHER inválido: %q, debe ser 'redis' o 'memory'", c.Events.Publisher))
+	}
+	if c.Events.Stream == "" {
+		errs = append(errs, errors.New("EVENT_STREAM no puede estar vacío"))
+	}
+
+	switch c.Idempotency.Store {
+	case "", "memory", "redis":
+	case "postgres":
+		if c.Storage.Driver != "postgres" {
+			errs = append(errs, errors.New("IDEMPOTENCY_STORE=postgres necesita STORAGE_DRIVER=postgres"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("IDEMPOTENCY_STORE inválido: %q, debe ser 'postgres', 'redis' o 'memory'", c.Idempotency.Store))
+	}
+	if c.Idempotency.TTL <= 0 {
+		errs = append(errs, errors.New("IDEMPOTENCY_TTL debe ser mayor a 0"))
+	}
+	if c.Idempotency.LockTimeout <= 0 || c.Idempotency.LockTimeout > c.Idempotency.TTL {
+		errs = append(errs, errors.New("IDEMPOTENCY_LOCK_TIMEOUT debe ser mayor a 0 y no mayor a IDEMPOTENCY_TTL"))
+	}
+
+	if c.UsesRedis() && c.Redis.Addr == "" {
+		errs = append(errs, errors.New("REDIS_ADDR es obligatorio con EVENT_PUBLISHER=redis o IDEMPOTENCY_STORE=redis"))
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateWorker checks everything cmd/worker needs
+func (c Config) ValidateWorker() error {
+	var errs []error
+	if c.Redis.Addr == "" {
+		errs = append(errs, errors.New("REDIS_ADDR es obligatorio"))
+	}
+	if c.Worker.Consumer == "" {
+		errs = append(errs, errors.New("WORKER_CONSUMER es obligatorio, no se pudo usar el nombre del host"))
+	}
+	if c.Worker.Stream == "" || c.Worker.Group == "" {
+		errs = append(errs, errors.New("WORKER_STREAM y WORKER_GROUP no pueden estar vacíos"))
+	}
+	if c.Worker.ClaimIdle <= 0 {
+		errs = append(errs, errors.New("WORKER_CLAIM_IDLE debe ser mayor a 0"))
+	}
+	if c.Worker.MaxAttempts < 1 {
+		errs = append(errs, errors.New("WORKER_MAX_ATTEMPTS debe ser mayor a 0"))
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateMigrate checks what `api migrate` needs, only the database
+func (c Config) ValidateMigrate() error {
+	if c.Storage.Driver == "memory" {
+		return errors.New("migrate necesita STORAGE_DRIVER=postgres o sqlite")
+	}
+	return errors.Join(c.validateDatabase()...)
+}
+
+// UsesRedis is true when the events or the Idempotency-Key responses go to redis
+func (c Config) UsesRedis() bool {
+	return c.Events.Publisher == "redis" || c.Idempotency.Store == "redis"
+}
+
+// the database of STORAGE_DRIVER, postgres or sqlite
+func (c Config) validateDatabase() []error {
+	switch c.Storage.Driver {
+	case "postgres":
+		return c.Database.validate()
+	case "sqlite":
+		if c.Database.Path == "" {
+			return []error{errors.New("DB_PATH es obligatorio con STORAGE_DRIVER=sqlite, un archivo o :memory:")}
+		}
+		return nil
+	default:
+		return []error{fmt.Errorf("STORAGE_DRIVER inválido: %q, debe ser 'postgres', 'sqlite' o 'memory'", c.Storage.Driver)}
+	}
+}
+
+func (d DatabaseConfig) validate() []error {
+	var errs []error
+	required := []struct {
+		key   string
+		value string
+	}{
+		{"DB_HOST", d.Host},
+		{"DB_PORT", d.Port},
+		{"DB_USER", d.User},
+		{"DB_NAME", d.Name},
+	}
+	for _, field := range required {
+		if field.value == "" {
+			errs = append(errs, fmt.Errorf("%s es obligatorio con STORAGE_DRIVER=postgres", field.key))
+		}
+	}
+	return errs
+}
+
+// DSN of the postgres driver, values are quoted so an empty password or one with spaces does
+// not swallow the next key
+func (d DatabaseConfig) DSN() string {
+	quote := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return fmt.Sprintf(
+		"host='%s' port='%s' user='%s' password='%s' dbname='%s' sslmode='%s'",
+		quote.Replace(d.Host), quote.Replace(d.Port), quote.Replace(d.User),
+		quote.Replace(d.Password), quote.Replace(d.Name), quote.Replace(d.SSLMode),
+	)
+}
+
+// SQLiteDSN is Path with foreign keys on, sqlite leaves them off by default
+func (d DatabaseConfig) SQLiteDSN() string {
+	separator := "?"
+	if strings.Contains(d.Path, "?") {
+		separator = "&"
+	}
+	return d.Path + separator + "_foreign_keys=1&_busy_timeout=5000"
+}
+
+// replaces the values that are set in the environment, keeps every parse error
+type envReader struct {
+	errs []error
+}
+
+func (r *envReader) str(key string, target *string) {
+	if value := os.Getenv(key); value != "" {
+		*target = value
+	}
+}
+
+func (r *envReader) duration(key string, target *time.Duration) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf("%s inválido: %q, debe ser una duración como 30s", key, value))
+		return
+	}
+	*target = duration
+}
+
+func (r *envReader) integer(key string, target *int64) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf("%s inválido: %q, debe ser un número", key, value))
+		return
+	}
+	*target = number
+}
+
+func (r *envReader) boolean(key string, target *bool) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf("%s inválido: %q, debe ser true o false", key, value))
+		return
+	}
+	*target = enabled
+}
@@ -0,0 +1,377 @@
+// internal/config/config_test.go
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// every variable Load reads
+var envKeys = []string{
+	"CONFIG_FILE", "RULES_FILE", "PORT", "CORS_ALLOWED_ORIGIN", "STORAGE_DRIVER",
+	"DB_HOST", "DB_PORT", "DB_USER", "DB_PASSWORD", "DB_NAME", "DB_SSLMODE", "DB_PATH", "DB_MIGRATE_ON_START",
+	"REDIS_ADDR", "EVENT_PUBLISHER", "EVENT_STREAM", "EVENT_STREAMS",
+	"IDEMPOTENCY_STORE", "IDEMPOTENCY_TTL", "IDEMPOTENCY_LOCK_TIMEOUT",
+	"RULES_MAX_DURATION", "RULES_MIN_LEAD_TIME", "RULES_WORKING_HOURS", "RULES_TIMEZONE",
+	"WORKER_CONSUMER", "WORKER_STREAM", "WORKER_GROUP", "WORKER_DEAD_LETTER_STREAM", "WORKER_CLAIM_IDLE", "WORKER_MAX_ATTEMPTS",
+}
+
+// runs the test in an empty directory without the variables of Load, t.Setenv puts them back
+// after the test, the ones the .env sets included
+func cleanEnv(t *testing.T) string {
+	t.Helper()
+	for _, key := range envKeys {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+	return dir
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// every error in want is in err, none means no error
+func checkErrors(t *testing.T, err error, want []string) {
+	t.Helper()
+	if len(want) == 0 {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return
+	}
+	if err == nil {
+		t.Fatalf("no error, want %v", want)
+	}
+	for _, text := range want {
+		if !strings.Contains(err.Error(), text) {
+			t.Fatalf("error %q does not mention %s", err, text)
+		}
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	const configFile = `
+port: "4000"
+events:
+  stream: yaml_stream
+database:
+  host: yaml-host
+rules:
+  max_duration: 3h
+  min_lead_time: 10m
+worker:
+  stream: yaml_worker
+`
+	const rulesFile = `{"max_duration": "4h", "working_hours": "08:00-18:00"}`
+
+	tests := []struct {
+		name   string
+		yaml   bool
+		rules  bool
+		dotenv string
+		env    map[string]string
+		want   func(cfg Config) Config
+	}{
+		{
+			name: "defaults",
+			want: func(cfg Config) Config { return cfg },
+		},
+		{
+			name: "CONFIG_FILE",
+			yaml: true,
+			want: func(cfg Config) Config {
+				cfg.Port = "4000"
+				cfg.Events.Stream = "yaml_stream"
+				cfg.Database.Host = "yaml-host"
+				cfg.Rules = RulesConfig{MaxDuration: "3h", MinLeadTime: "10m"}
+				cfg.Worker.Stream = "yaml_worker"
+				return cfg
+			},
+		},
+		{
+			name:  "RULES_FILE replaces the rules it has",
+			yaml:  true,
+			rules: true,
+			want: func(cfg Config) Config {
+				cfg.Port = "4000"
+				cfg.Events.Stream = "yaml_stream"
+				cfg.Database.Host = "yaml-host"
+				cfg.Rules = RulesConfig{MaxDuration: "4h", MinLeadTime: "10m", WorkingHours: "08:00-18:00"}
+				cfg.Worker.Stream = "yaml_worker"
+				return cfg
+			},
+		},
+		{
+			name:   ".env",
+			yaml:   true,
+			rules:  true,
+			dotenv: "PORT=5000\nRULES_MAX_DURATION=5h\nDB_HOST=dotenv-host\n",
+			want: func(cfg Config) Config {
+				cfg.Port = "5000"
+				cfg.Events.Stream = "yaml_stream"
+				cfg.Database.Host = "dotenv-host"
+				cfg.Rules = RulesConfig{MaxDuration: "5h", MinLeadTime: "10m", WorkingHours: "08:00-18:00"}
+				cfg.Worker.Stream = "yaml_worker"
+				return cfg
+			},
+		},
+		{
+			name:   "environment",
+			yaml:   true,
+			rules:  true,
+			dotenv: "PORT=5000\nRULES_MAX_DURATION=5h\nDB_HOST=dotenv-host\n",
+			env:    map[string]string{"PORT": "6000", "RULES_MAX_DURATION": "6h", "EVENT_STREAM": "env_stream"},
+			want: func(cfg Config) Config {
+				cfg.Port = "6000"
+				cfg.Events.Stream = "env_stream"
+				cfg.Database.Host = "dotenv-host"
+				cfg.Rules = RulesConfig{MaxDuration: "6h", MinLeadTime: "10m", WorkingHours: "08:00-18:00"}
+				cfg.Worker.Stream = "yaml_worker"
+				return cfg
+			},
+		},
+		{
+			name:   "CONFIG_FILE from the .env",
+			dotenv: "CONFIG_FILE=config.yaml\n",
+			want: func(cfg Config) Config {
+				cfg.Port = "4000"
+				cfg.Events.Stream = "yaml_stream"
+				cfg.Database.Host = "yaml-host"
+				cfg.Rules = RulesConfig{MaxDuration: "3h", MinLeadTime: "10m"}
+				cfg.Worker.Stream = "yaml_worker"
+				return cfg
+			},
+		},
+		{
+			name: "typed values",
+			env: map[string]string{
+				"DB_MIGRATE_ON_START": "true",
+				"IDEMPOTENCY_TTL":     "1h",
+				"WORKER_MAX_ATTEMPTS": "9",
+				"WORKER_CONSUMER":     "worker-1",
+				"WORKER_STREAM":       "orders",
+			},
+			want: func(cfg Config) Config {
+				cfg.Database.MigrateOnStart = true
+				cfg.Idempotency.TTL = time.Hour
+				cfg.Worker.MaxAttempts = 9
+				cfg.Worker.Consumer = "worker-1"
+				cfg.Worker.Stream = "orders"
+				return cfg
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := cleanEnv(t)
+			// the file is always there, CONFIG_FILE decides if it is read
+			configPath := writeFile(t, dir, "config.yaml", configFile)
+			if tc.yaml {
+				t.Setenv("CONFIG_FILE", configPath)
+			}
+			if tc.rules {
+				t.Setenv("RULES_FILE", writeFile(t, dir, "rules.json", rulesFile))
+			}
+			if tc.dotenv != "" {
+				writeFile(t, dir, ".env", tc.dotenv)
+			}
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			cfg, err := Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := tc.want(Default())
+			// filled by Load when empty
+			if want.Worker.Consumer == "" {
+				want.Worker.Consumer, _ = os.Hostname()
+			}
+			want.Worker.DeadLetterStream = want.Worker.Stream + "_dead"
+			if cfg != want {
+				t.Fatalf("got %+v\nwant %+v", cfg, want)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		env   map[string]string
+		want  []string
+	}{
+		{
+			name: "CONFIG_FILE missing",
+			env:  map[string]string{"CONFIG_FILE": "missing.yaml"},
+			want: []string{"missing.yaml"},
+		},
+		{
+			name:  "CONFIG_FILE invalid",
+			files: map[string]string{"config.yaml": "port: [3000"},
+			env:   map[string]string{"CONFIG_FILE": "config.yaml"},
+			want:  []string{"config.yaml"},
+		},
+		{
+			name:  "RULES_FILE invalid",
+			files: map[string]string{"rules.json": `{"max_duration": 2}`},
+			env:   map[string]string{"RULES_FILE": "rules.json"},
+			want:  []string{"rules.json"},
+		},
+		{
+			name:  "invalid .env",
+			files: map[string]string{".env": "PORT=\"3000\n"},
+			want:  []string{".env"},
+		},
+		{
+			name: "every value that can not be parsed",
+			env: map[string]string{
+				"IDEMPOTENCY_TTL":     "un día",
+				"WORKER_CLAIM_IDLE":   "30",
+				"WORKER_MAX_ATTEMPTS": "cinco",
+				"DB_MIGRATE_ON_START": "sí",
+			},
+			want: []string{"IDEMPOTENCY_TTL", "WORKER_CLAIM_IDLE", "WORKER_MAX_ATTEMPTS", "DB_MIGRATE_ON_START"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := cleanEnv(t)
+			for name, content := range tc.files {
+				writeFile(t, dir, name, content)
+			}
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := Load()
+			checkErrors(t, err, tc.want)
+		})
+	}
+}
+
+// postgres with redis, everything the api needs
+func validAPIConfig() Config {
+	cfg := Default()
+	cfg.Database = DatabaseConfig{Host: "localhost", Port: "5432", User: "postgres", Name: "prueba", SSLMode: "disable"}
+	cfg.Redis.Addr = "localhost:6379"
+	return cfg
+}
+
+func TestValidateAPI(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(cfg *Config)
+		want []string
+	}{
+		{name: "postgres and redis"},
+		{name: "all in memory", edit: func(c *Config) {
+			c.Storage.Driver = "memory"
+			c.Database = DatabaseConfig{}
+			c.Events.Publisher = "memory"
+			c.Redis.Addr = ""
+		}},
+		{name: "sqlite", edit: func(c *Config) { c.Storage.Driver = "sqlite"; c.Database = DatabaseConfig{Path: ":memory:"} }},
+		{name: "sqlite without path", edit: func(c *Config) { c.Storage.Driver = "sqlite"; c.Database.Path = "" }, want: []string{"DB_PATH"}},
+		{name: "unknown driver", edit: func(c *Config) { c.Storage.Driver = "mysql" }, want: []string{"STORAGE_DRIVER"}},
+		{name: "postgres without database", edit: func(c *Config) { c.Database = DatabaseConfig{} }, want: []string{"DB_HOST", "DB_PORT", "DB_USER", "DB_NAME"}},
+		{name: "port not a number", edit: func(c *Config) { c.Port = "http" }, want: []string{"PORT"}},
+		{name: "port out of range", edit: func(c *Config) { c.Port = "70000" }, want: []string{"PORT"}},
+		{name: "unknown publisher", edit: func(c *Config) { c.Events.Publisher = "kafka" }, want: []string{"EVENT_PUBLISHER"}},
+		{name: "empty stream", edit: func(c *Config) { c.Events.Stream = "" }, want: []string{"EVENT_STREAM"}},
+		{name: "redis publisher without address", edit: func(c *Config) { c.Redis.Addr = "" }, want: []string{"REDIS_ADDR"}},
+		{name: "redis idempotency without address", edit: func(c *Config) {
+			c.Events.Publisher = "memory"
+			c.Idempotency.Store = "redis"
+			c.Redis.Addr = ""
+		}, want: []string{"REDIS_ADDR"}},
+		{name: "postgres idempotency with sqlite", edit: func(c *Config) {
+			c.Storage.Driver = "sqlite"
+			c.Idempotency.Store = "postgres"
+		}, want: []string{"IDEMPOTENCY_STORE"}},
+		{name: "unknown idempotency store", edit: func(c *Config) { c.Idempotency.Store = "file" }, want: []string{"IDEMPOTENCY_STORE"}},
+		{name: "no ttl", edit: func(c *Config) { c.Idempotency.TTL = 0 }, want: []string{"IDEMPOTENCY_TTL"}},
+		{name: "lock longer than the ttl", edit: func(c *Config) { c.Idempotency.LockTimeout = 25 * time.Hour }, want: []string{"IDEMPOTENCY_LOCK_TIMEOUT"}},
+		{name: "every error together", edit: func(c *Config) {
+			c.Port = ""
+			c.Events.Publisher = ""
+			c.Idempotency.TTL = 0
+		}, want: []string{"PORT", "EVENT_PUBLISHER", "IDEMPOTENCY_TTL"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := validAPIConfig()
+			if tc.edit != nil {
+				tc.edit(&cfg)
+			}
+			checkErrors(t, cfg.ValidateAPI(), tc.want)
+		})
+	}
+}
+
+func TestValidateWorker(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(cfg *Config)
+		want []string
+	}{
+		{name: "valid"},
+		{name: "without redis", edit: func(c *Config) { c.Redis.Addr = "" }, want: []string{"REDIS_ADDR"}},
+		{name: "without consumer", edit: func(c *Config) { c.Worker.Consumer = "" }, want: []string{"WORKER_CONSUMER"}},
+		{name: "without group", edit: func(c *Config) { c.Worker.Group = "" }, want: []string{"WORKER_GROUP"}},
+		{name: "no claim idle", edit: func(c *Config) { c.Worker.ClaimIdle = 0 }, want: []string{"WORKER_CLAIM_IDLE"}},
+		{name: "no attempts", edit: func(c *Config) { c.Worker.MaxAttempts = 0 }, want: []string{"WORKER_MAX_ATTEMPTS"}},
+		// the database is not used by the worker
+		{name: "without database", edit: func(c *Config) { c.Storage.Driver = "mysql"; c.Database = DatabaseConfig{} }},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Redis.Addr = "localhost:6379"
+			cfg.Worker.Consumer = "worker-1"
+			if tc.edit != nil {
+				tc.edit(&cfg)
+			}
+			checkErrors(t, cfg.ValidateWorker(), tc.want)
+		})
+	}
+}
+
+func TestValidateMigrate(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(cfg *Config)
+		want []string
+	}{
+		{name: "postgres"},
+		{name: "sqlite", edit: func(c *Config) { c.Storage.Driver = "sqlite"; c.Database = DatabaseConfig{Path: "test.db"} }},
+		{name: "memory", edit: func(c *Config) { c.Storage.Driver = "memory" }, want: []string{"STORAGE_DRIVER"}},
+		{name: "postgres without host", edit: func(c *Config) { c.Database.Host = "" }, want: []string{"DB_HOST"}},
+		// redis and the events are not used by migrate
+		{name: "without redis", edit: func(c *Config) { c.Redis.Addr = ""; c.Events.Publisher = "kafka" }},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := validAPIConfig()
+			if tc.edit != nil {
+				tc.edit(&cfg)
+			}
+			checkErrors(t, cfg.ValidateMigrate(), tc.want)
+		})
+	}
+}
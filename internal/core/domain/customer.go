@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Customer struct {
@@ -13,9 +14,13 @@ type Customer struct {
 	LastName  string    `gorm:"not null"`
 	Address   string    `gorm:"not null"`
 	//puntero para poder capturar el nil
-	StartDate  *time.Time
-	EndDate    *time.Time
-	IsActive   bool      `gorm:"not null; default:false"`
-	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	StartDate *time.Time
+	EndDate   *time.Time
+	IsActive  bool      `gorm:"not null; default:false"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	//sube en cada actualización, control de concurrencia optimista
+	Version int `gorm:"not null;default:1"`
+	//borrado lógico, gorm oculta los clientes con deleted_at en todas las consultas
+	DeletedAt  gorm.DeletedAt `gorm:"index" swaggertype:"string" format:"date-time"`
 	WorkOrders []WorkOrder
 }
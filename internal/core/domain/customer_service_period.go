@@ -0,0 +1,33 @@
+// internal/core/domain/customer_service_period.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CustomerServicePeriod is one interval in which the customer was active, from the activation
+// to the cancellation
+type CustomerServicePeriod struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	CustomerID uuid.UUID `gorm:"type:uuid;not null"`
+	StartedAt  time.Time `gorm:"not null"`
+	//nil mientras el cliente sigue activo
+	EndedAt *time.Time
+	//órdenes que abrieron y cerraron el periodo, nil en los periodos anteriores a la migración 013
+	StartWorkOrderID *uuid.UUID `gorm:"type:uuid"`
+	EndWorkOrderID   *uuid.UUID `gorm:"type:uuid"`
+}
+
+// Duration of the period, until now while it is open
+func (p CustomerServicePeriod) Duration(now time.Time) time.Duration {
+	end := now
+	if p.EndedAt != nil {
+		end = *p.EndedAt
+	}
+	if end.Before(p.StartedAt) {
+		return 0
+	}
+	return end.Sub(p.StartedAt)
+}
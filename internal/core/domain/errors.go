@@ -0,0 +1,90 @@
+// internal/core/domain/errors.go
+package domain
+
+type ErrorKind string
+
+const (
+	// the request has invalid data
+	KindValidation ErrorKind = "validation"
+	// the resource does not exist
+	KindNotFound ErrorKind = "not_found"
+	// the request is valid but the current state does not allow it
+	KindConflict ErrorKind = "conflict"
+	// the request is well formed but can not be processed as it is
+	KindUnprocessable ErrorKind = "unprocessable"
+	// the client expected another version of the resource
+	KindPrecondition ErrorKind = "precondition"
+)
+
+// Error is a business error. Code is stable and meant for programs, Message is meant for people
+// and can change. Declare them once as vars so errors.Is can compare them
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	// what is wrong with each field, only for validation errors with more than one problem
+	Fields []FieldError
+	// configured business rule that failed and its limit, only for rule errors
+	Rule  string
+	Limit string
+}
+
+// FieldError is the problem of a single field of the request, Field uses the json name
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func NewValidationError(code, message string) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message}
+}
+
+// validation error that lists the problem of every field
+func NewFieldsError(fields []FieldError) *Error {
+	return &Error{Kind: KindValidation, Code: "invalid_fields", Message: "uno o más campos son inválidos", Fields: fields}
+}
+
+// validation error of a configurable rule, limit is the configured value as text ej. 2h
+func NewRuleError(code, message, rule, limit string) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message, Rule: rule, Limit: limit}
+}
+
+func NewNotFoundError(code, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+func NewConflictError(code, message string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message}
+}
+
+func NewUnprocessableError(code, message string) *Error {
+	return &Error{Kind: KindUnprocessable, Code: code, Message: message}
+}
+
+func NewPreconditionError(code, message string) *Error {
+	return &Error{Kind: KindPrecondition, Code: code, Message: message}
+}
+
+var (
+	// returned by repositories when the row does not exist
+	ErrCustomerNotFound  = NewNotFoundError("customer_not_found", "cliente no encontrado")
+	ErrWorkOrderNotFound = NewNotFoundError("work_order_not_found", "orden no encontrada")
+
+	// returned by repositories when a unique constraint fails
+	ErrDuplicated = NewConflictError("duplicated", "ya existe un registro con los mismos datos")
+
+	// returned by repositories when the planned window of an open order overlaps another open
+	// order of the same customer
+	ErrScheduleOverlap = NewConflictError("work_order_overlap", "el cliente ya tiene una orden abierta planeada en un horario que se cruza con este")
+
+	// returned by repositories when the row changed after it was read
+	ErrVersionConflict = NewConflictError("concurrent_update", "el registro fue modificado por otra petición, vuelva a consultarlo e intente de nuevo")
+
+	// returned by repositories when a foreign key points to a row that does not exist
+	ErrRelatedNotFound = NewValidationError("related_not_found", "uno de los registros relacionados no existe")
+)
@@ -0,0 +1,35 @@
+// internal/core/domain/idempotency.go
+package domain
+
+import "time"
+
+// IdempotencyKey is the response stored for a request sent with an Idempotency-Key header,
+// retries with the same key get it again instead of running the request twice
+type IdempotencyKey struct {
+	Key string `gorm:"primaryKey"`
+	//hash del método, la ruta y el cuerpo, la misma llave con otra petición se rechaza
+	Fingerprint string `gorm:"not null"`
+	StatusCode  int    `gorm:"not null;default:0"`
+	ContentType string `gorm:"not null;default:''"`
+	//headers de la respuesta que se repiten con ella, ej. ETag y Location
+	Headers map[string]string `gorm:"serializer:json;type:jsonb"`
+	Body    []byte
+	//nil mientras la primera petición sigue en curso
+	CompletedAt *time.Time
+	//hasta cuándo la primera petición tiene la llave, si no terminó para entonces otra la puede tomar
+	LockedUntil time.Time `gorm:"not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	ExpiresAt   time.Time `gorm:"not null"`
+}
+
+// Abandoned is true when the first request never finished, ej. the server stopped between
+// reserving the key and storing the response
+func (k IdempotencyKey) Abandoned(now time.Time) bool {
+	return k.CompletedAt == nil && !k.LockedUntil.After(now)
+}
+
+// HeldBy is true while the request that got reserved can still complete or release the key,
+// false once it was completed or another retry took it over after the lock expired
+func (k IdempotencyKey) HeldBy(reserved IdempotencyKey) bool {
+	return k.CompletedAt == nil && k.Fingerprint == reserved.Fingerprint && k.LockedUntil.Equal(reserved.LockedUntil)
+}
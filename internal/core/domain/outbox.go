@@ -0,0 +1,20 @@
+// internal/core/domain/outbox.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxMessage is an event saved in the same transaction as the change that produced it,
+// the relay sends it to the stream later
+type OutboxMessage struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Event       string    `gorm:"not null"`
+	Payload     string    `gorm:"type:jsonb;not null"`
+	Attempts    int       `gorm:"not null;default:0"`
+	LastError   string    `gorm:"not null;default:''"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	PublishedAt *time.Time
+}
@@ -22,6 +22,11 @@ const (
 	TypeCancell  Type = "cancelar cliente"
 )
 
+// only the two types of the business logic are valid
+func (t Type) IsValid() bool {
+	return t == TypeActivate || t == TypeCancell
+}
+
 type WorkOrder struct {
 	ID               uuid.UUID `gorm:"type:uuid;primaryKey"`
 	CustomerID       uuid.UUID `gorm:"type:uuid;not null"`
@@ -32,4 +37,8 @@ type WorkOrder struct {
 	Status           Status    `gorm:"type:work_order_status;default:'new';not null"`
 	Type             Type      `gorm:"not null"` //debido a la logica de negocio, definimos a type como dos valores, pero realmente a gorm le mandamos un string, si se maneja con un enum o un default, podria causar errores en el futuro
 	CreatedAt        time.Time `gorm:"autoCreateTime"`
+	//sube en cada actualización, control de concurrencia optimista
+	Version int `gorm:"not null;default:1"`
+	//solo se llena cuando la orden se cancela
+	CancellationReason *string
 }
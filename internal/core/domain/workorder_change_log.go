@@ -0,0 +1,19 @@
+// internal/core/domain/workorder_change_log.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkOrderChangeLog keeps who changed a field of a work order and its values before and after
+type WorkOrderChangeLog struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	WorkOrderID uuid.UUID `gorm:"type:uuid;not null"`
+	Actor       string    `gorm:"not null"`
+	Field       string    `gorm:"not null"`
+	OldValue    string    `gorm:"not null"`
+	NewValue    string    `gorm:"not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
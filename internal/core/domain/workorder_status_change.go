@@ -0,0 +1,19 @@
+// internal/core/domain/workorder_status_change.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkOrderStatusChange is one step in the status history of a work order, who moved it and when
+type WorkOrderStatusChange struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	WorkOrderID uuid.UUID `gorm:"type:uuid;not null"`
+	Actor       string    `gorm:"not null"`
+	//nil cuando la orden se crea
+	OldStatus *Status   `gorm:"type:work_order_status"`
+	NewStatus Status    `gorm:"type:work_order_status;not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
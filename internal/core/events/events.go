@@ -0,0 +1,106 @@
+// internal/core/events/events.go
+
+// Package events has the contract of the messages sent to the stream. Every message is an
+// Envelope whose payload matches the json schema of its type and version in schemas/
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// event sent when a customer is created
+	CustomerCreated = "customer_created"
+
+	// event sent when a work order activates a customer
+	CustomerActivated = "customer_activated"
+
+	// event sent when a work order cancels a customer
+	CustomerDeactivated = "customer_deactivated"
+
+	// event sent when a workOrder is created
+	WorkOrderCreated = "work_order_created"
+
+	// event sent when the planned dates of a workOrder change
+	WorkOrderRescheduled = "work_order_rescheduled"
+
+	// event sent when a workOrder is completed
+	WorkOrderCompleted = "work_order_completed"
+
+	// event sent when a workOrder is cancelled
+	WorkOrderCancelled = "work_order_cancelled"
+)
+
+// current schema version of each event, a breaking change of a payload needs a new version
+// and a new file in schemas/
+var schemaVersions = map[string]int{
+	CustomerCreated:      1,
+	CustomerActivated:    1,
+	CustomerDeactivated:  1,
+	WorkOrderCreated:     1,
+	WorkOrderRescheduled: 1,
+	WorkOrderCompleted:   1,
+	WorkOrderCancelled:   1,
+}
+
+// Types returns every event type, ej. to register handlers or check the stream configuration
+func Types() []string {
+	types := make([]string, 0, len(schemaVersions))
+	for eventType := range schemaVersions {
+		types = append(types, eventType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+type Envelope struct {
+	ID            uuid.UUID       `json:"id"`
+	Type          string          `json:"type"`
+	SchemaVersion int             `json:"schema_version"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	AggregateID   uuid.UUID       `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// New wraps payload in an envelope of the current version of eventType, aggregateID is the
+// entity that changed
+func New(eventType string, aggregateID uuid.UUID, payload any) (Envelope, error) {
+	version, ok := schemaVersions[eventType]
+	if !ok {
+		return Envelope{}, fmt.Errorf("evento desconocido: %s", eventType)
+	}
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	return Envelope{
+		ID:            uuid.New(),
+		Type:          eventType,
+		SchemaVersion: version,
+		OccurredAt:    time.Now().UTC(),
+		AggregateID:   aggregateID,
+		Payload:       payloadJSON,
+	}, nil
+}
+
+// Encode serializes the envelope. It is not checked against the schema here, events_test.go
+// checks every payload so a drift fails in the tests and not when the event is written
+func (e Envelope) Encode() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// Decode reads an envelope sent to the stream, the payload is left for the handler of its type
+func Decode(data []byte) (Envelope, error) {
+	var envelope Envelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return Envelope{}, err
+	}
+	return envelope, nil
+}
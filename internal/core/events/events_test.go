@@ -0,0 +1,139 @@
+// internal/core/events/events_test.go
+
+package events
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+)
+
+// payloads as the services build them, every event type needs at least one. Optional fields go
+// empty and filled
+func payloadCases() []struct {
+	name    string
+	event   string
+	payload any
+} {
+	now := time.Now()
+	later := now.Add(time.Hour)
+	reason := "el cliente no estaba en casa"
+
+	customer := domain.Customer{ID: uuid.New(), FirstName: "Ana", LastName: "Pérez", Address: "calle 1"}
+	active := customer
+	active.IsActive = true
+	active.StartDate = &now
+	inactive := active
+	inactive.IsActive = false
+	inactive.EndDate = &later
+
+	workOrder := domain.WorkOrder{
+		ID:               uuid.New(),
+		CustomerID:       customer.ID,
+		Description:      "instalación",
+		PlannedDateBegin: now,
+		PlannedDateEnd:   later,
+		Status:           domain.StatusNew,
+		Type:             domain.TypeActivate,
+	}
+	done := workOrder
+	done.Status = domain.StatusDone
+	cancelled := workOrder
+	cancelled.Status = domain.StatusCancelled
+	cancelled.Type = domain.TypeCancell
+	cancelled.CancellationReason = &reason
+
+	return []struct {
+		name    string
+		event   string
+		payload any
+	}{
+		{"customer created", CustomerCreated, NewCustomerPayload(customer)},
+		{"customer activated", CustomerActivated, NewCustomerStatusPayload(active, workOrder.ID)},
+		{"customer deactivated", CustomerDeactivated, NewCustomerStatusPayload(inactive, workOrder.ID)},
+		{"work order created", WorkOrderCreated, NewWorkOrderPayload(workOrder)},
+		{"work order rescheduled", WorkOrderRescheduled, NewWorkOrderRescheduledPayload(workOrder, now.Add(-time.Hour), now)},
+		{"work order completed", WorkOrderCompleted, NewWorkOrderPayload(done)},
+		{"work order cancelled", WorkOrderCancelled, NewWorkOrderPayload(cancelled)},
+	}
+}
+
+// a field added, renamed or removed in a payload without its schema fails here
+func TestPayloadsMatchSchemas(t *testing.T) {
+	covered := make(map[string]bool)
+
+	for _, tc := range payloadCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			envelope, err := New(tc.event, uuid.New(), tc.payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := envelope.Encode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Validate(data); err != nil {
+				t.Fatal(err)
+			}
+		})
+		covered[tc.event] = true
+	}
+
+	for _, eventType := range Types() {
+		if !covered[eventType] {
+			t.Errorf("%s has no payload in payloadCases", eventType)
+		}
+	}
+}
+
+func TestValidateRejectsDrift(t *testing.T) {
+	envelope, err := New(WorkOrderCompleted, uuid.New(), NewWorkOrderPayload(domain.WorkOrder{
+		ID:         uuid.New(),
+		CustomerID: uuid.New(),
+		Status:     domain.StatusDone,
+		Type:       domain.TypeActivate,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(envelope.Payload, &payload); err != nil {
+		t.Fatal(err)
+	}
+	// a field the schema does not know and one it needs missing
+	payload["priority"] = "alta"
+	delete(payload, "customer_id")
+	envelope.Payload, _ = json.Marshal(payload)
+
+	data, err := envelope.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = Validate(data)
+	if err == nil || !strings.Contains(err.Error(), schemaName(WorkOrderCompleted, 1)) {
+		t.Fatalf("got %v, want an error of %s", err, schemaName(WorkOrderCompleted, 1))
+	}
+}
+
+func TestDecodeReadsEncode(t *testing.T) {
+	envelope, err := New(CustomerCreated, uuid.New(), NewCustomerPayload(domain.Customer{ID: uuid.New()}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := envelope.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.ID != envelope.ID || decoded.Type != envelope.Type || decoded.SchemaVersion != envelope.SchemaVersion || decoded.AggregateID != envelope.AggregateID {
+		t.Fatalf("decoded %+v, want %+v", decoded, envelope)
+	}
+}
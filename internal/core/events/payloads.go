@@ -0,0 +1,90 @@
+// internal/core/events/payloads.go
+
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+)
+
+// payloads are copies of the domain structs with only what consumers need, a change in the
+// domain does not reach the stream until it is mapped here and in the schema
+
+// payload of customer_created
+type CustomerPayload struct {
+	ID        uuid.UUID  `json:"id"`
+	FirstName string     `json:"first_name"`
+	LastName  string     `json:"last_name"`
+	Address   string     `json:"address"`
+	IsActive  bool       `json:"is_active"`
+	StartDate *time.Time `json:"start_date"`
+	EndDate   *time.Time `json:"end_date"`
+}
+
+func NewCustomerPayload(customer domain.Customer) CustomerPayload {
+	return CustomerPayload{
+		ID:        customer.ID,
+		FirstName: customer.FirstName,
+		LastName:  customer.LastName,
+		Address:   customer.Address,
+		IsActive:  customer.IsActive,
+		StartDate: customer.StartDate,
+		EndDate:   customer.EndDate,
+	}
+}
+
+// payload of customer_activated and customer_deactivated, WorkOrderID is the order that
+// changed the customer
+type CustomerStatusPayload struct {
+	CustomerPayload
+	WorkOrderID uuid.UUID `json:"work_order_id"`
+}
+
+func NewCustomerStatusPayload(customer domain.Customer, workOrderID uuid.UUID) CustomerStatusPayload {
+	return CustomerStatusPayload{
+		CustomerPayload: NewCustomerPayload(customer),
+		WorkOrderID:     workOrderID,
+	}
+}
+
+// payload of work_order_created, work_order_completed and work_order_cancelled
+type WorkOrderPayload struct {
+	ID                 uuid.UUID `json:"id"`
+	CustomerID         uuid.UUID `json:"customer_id"`
+	Type               string    `json:"type"`
+	Status             string    `json:"status"`
+	Description        string    `json:"description"`
+	PlannedDateBegin   time.Time `json:"planned_date_begin"`
+	PlannedDateEnd     time.Time `json:"planned_date_end"`
+	CancellationReason *string   `json:"cancellation_reason,omitempty"`
+}
+
+func NewWorkOrderPayload(workOrder domain.WorkOrder) WorkOrderPayload {
+	return WorkOrderPayload{
+		ID:                 workOrder.ID,
+		CustomerID:         workOrder.CustomerID,
+		Type:               string(workOrder.Type),
+		Status:             string(workOrder.Status),
+		Description:        workOrder.Description,
+		PlannedDateBegin:   workOrder.PlannedDateBegin,
+		PlannedDateEnd:     workOrder.PlannedDateEnd,
+		CancellationReason: workOrder.CancellationReason,
+	}
+}
+
+// payload of work_order_rescheduled, the dates before the change go with the new ones
+type WorkOrderRescheduledPayload struct {
+	WorkOrderPayload
+	PreviousPlannedDateBegin time.Time `json:"previous_planned_date_begin"`
+	PreviousPlannedDateEnd   time.Time `json:"previous_planned_date_end"`
+}
+
+func NewWorkOrderRescheduledPayload(workOrder domain.WorkOrder, previousBegin, previousEnd time.Time) WorkOrderRescheduledPayload {
+	return WorkOrderRescheduledPayload{
+		WorkOrderPayload:         NewWorkOrderPayload(workOrder),
+		PreviousPlannedDateBegin: previousBegin,
+		PreviousPlannedDateEnd:   previousEnd,
+	}
+}
@@ -0,0 +1,83 @@
+// internal/core/events/schema.go
+
+package events
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+const envelopeSchema = "envelope.json"
+
+// compiled once, a schema that does not compile is a bug so it panics on start
+var schemas = compileSchemas()
+
+func compileSchemas() map[string]*jsonschema.Schema {
+	compiler := jsonschema.NewCompiler()
+	// uuid and date-time are checked, not only documented
+	compiler.AssertFormat = true
+
+	entries, err := schemaFiles.ReadDir("schemas")
+	if err != nil {
+		panic(err)
+	}
+	for _, entry := range entries {
+		data, err := schemaFiles.ReadFile(path.Join("schemas", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		if err := compiler.AddResource(entry.Name(), bytes.NewReader(data)); err != nil {
+			panic(err)
+		}
+	}
+
+	compiled := make(map[string]*jsonschema.Schema, len(entries))
+	for _, entry := range entries {
+		compiled[entry.Name()] = compiler.MustCompile(entry.Name())
+	}
+
+	// every event needs the schema of its current version
+	for eventType, version := range schemaVersions {
+		if _, ok := compiled[schemaName(eventType, version)]; !ok {
+			panic(fmt.Sprintf("falta el schema %s", schemaName(eventType, version)))
+		}
+	}
+	return compiled
+}
+
+// file of the payload schema, ej. work_order_completed.v1.json
+func schemaName(eventType string, version int) string {
+	return fmt.Sprintf("%s.v%d.json", eventType, version)
+}
+
+// Validate checks a serialized envelope and its payload against their schemas
+func Validate(data []byte) error {
+	var document map[string]any
+	if err := json.Unmarshal(data, &document); err != nil {
+		return err
+	}
+	if err := schemas[envelopeSchema].Validate(document); err != nil {
+		return fmt.Errorf("el sobre del evento no cumple su schema: %w", err)
+	}
+
+	eventType, _ := document["type"].(string)
+	// json numbers are float64
+	version, _ := document["schema_version"].(float64)
+	name := schemaName(eventType, int(version))
+	schema, ok := schemas[name]
+	if !ok {
+		return fmt.Errorf("no existe el schema %s", name)
+	}
+	if err := schema.Validate(document["payload"]); err != nil {
+		return fmt.Errorf("el payload de %s no cumple su schema: %w", name, err)
+	}
+	return nil
+}
@@ -10,24 +10,168 @@ import (
 	"github.com/krud3/prueba-tecnica/internal/core/domain"
 )
 
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// position of the last row returned, next page starts after it. Pages are keyset on (created_at, id)
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// Limit <= 0 means no limit, Cursor nil means first page, Sort empty means SortAsc
+type Pagination struct {
+	Limit  int
+	Cursor *Cursor
+	Sort   SortOrder
+}
+
+// one page of rows, NextCursor is nil on the last page and Total counts every row that
+// matches without pagination
+type Page[T any] struct {
+	Items      []T
+	NextCursor *Cursor
+	Total      int64
+}
+
+// every filter set is combined with AND, Since and Until are on the planned dates
 type WorkOrderFilters struct {
-	Since  *time.Time
-	Until  *time.Time
-	Status *domain.Status
+	Since      *time.Time
+	Until      *time.Time
+	Status     *domain.Status
+	CustomerID *uuid.UUID
+	Type       *domain.Type
+	// case insensitive text searched inside Description, empty means no search
+	Search       string
+	CreatedSince *time.Time
+	CreatedUntil *time.Time
+	Pagination
+}
+
+// fields of a customer that can be edited, nil means no change. IsActive, StartDate and EndDate
+// are missing on purpose, they only change when a work order is completed
+type CustomerChanges struct {
+	FirstName *string
+	LastName  *string
+	Address   *string
+}
+
+// fields of a work order that can be edited while it is new, nil means no change
+type WorkOrderChanges struct {
+	Description      *string
+	PlannedDateBegin *time.Time
+	PlannedDateEnd   *time.Time
+}
+
+// two open orders of the same customer whose planned windows overlap
+type WorkOrderConflict struct {
+	WorkOrder     domain.WorkOrder
+	ConflictsWith domain.WorkOrder
+}
+
+// every active period of a customer, oldest first, and the days it was active in total
+type CustomerServiceHistory struct {
+	Periods    []domain.CustomerServicePeriod
+	ActiveDays int
 }
 
 type CustomerRepository interface {
 	Create(ctx context.Context, customer domain.Customer) error
 	FindByID(ctx context.Context, id uuid.UUID) (*domain.Customer, error)
-	GetActive(ctx context.Context) ([]domain.Customer, error)
-	GetAll(ctx context.Context) ([]domain.Customer, error)
+	GetActive(ctx context.Context, pagination Pagination) (Page[domain.Customer], error)
+	GetAll(ctx context.Context, pagination Pagination) (Page[domain.Customer], error)
+	// saves only if the stored version is still customer.Version and moves it to Version+1,
+	// ErrVersionConflict when another update got there first
 	Update(ctx context.Context, customer domain.Customer) error
+	// soft delete, the customer is hidden but its row and work orders stay. Same check as
+	// Update, ErrVersionConflict if the stored version is no longer version
+	Delete(ctx context.Context, id uuid.UUID, version int) error
 }
 
 type WorkOrderRepository interface {
 	Create(ctx context.Context, workOrder domain.WorkOrder) error
 	FindByID(ctx context.Context, id uuid.UUID) (*domain.WorkOrder, error)
-	FindByFilter(ctx context.Context, filters WorkOrderFilters) ([]domain.WorkOrder, error)
-	FindByCustomerID(ctx context.Context, customerID uuid.UUID) ([]domain.WorkOrder, error)
+	FindByFilter(ctx context.Context, filters WorkOrderFilters) (Page[domain.WorkOrder], error)
+	FindByCustomerID(ctx context.Context, customerID uuid.UUID, pagination Pagination) (Page[domain.WorkOrder], error)
+	// same as CustomerRepository.Update, checks and moves workOrder.Version
 	Update(ctx context.Context, workOrder domain.WorkOrder) error
+	// open orders of the customer whose window overlaps [begin, end), excludeID is left out so a
+	// rescheduled order does not overlap itself
+	FindOverlapping(ctx context.Context, customerID uuid.UUID, begin, end time.Time, excludeID uuid.UUID) ([]domain.WorkOrder, error)
+	// pairs of overlapping open orders with some part of their window inside [since, until),
+	// customerID nil means every customer
+	FindConflicts(ctx context.Context, since, until time.Time, customerID *uuid.UUID) ([]WorkOrderConflict, error)
+}
+
+type OutboxRepository interface {
+	Create(ctx context.Context, message domain.OutboxMessage) error
+	// messages not published yet, oldest first. Inside a unit of work they stay locked until it
+	// ends and other transactions skip them, it returns none when an older message is locked
+	FindPending(ctx context.Context, limit int) ([]domain.OutboxMessage, error)
+	MarkPublished(ctx context.Context, id uuid.UUID) error
+	// counts the attempt and keeps the reason so the message is retried later
+	MarkFailed(ctx context.Context, id uuid.UUID, reason string) error
+}
+
+type WorkOrderChangeLogRepository interface {
+	Create(ctx context.Context, changeLog domain.WorkOrderChangeLog) error
+	FindByWorkOrderID(ctx context.Context, workOrderID uuid.UUID) ([]domain.WorkOrderChangeLog, error)
+}
+
+type WorkOrderStatusChangeRepository interface {
+	Create(ctx context.Context, change domain.WorkOrderStatusChange) error
+	// oldest first, the creation of the order is the first one
+	FindByWorkOrderID(ctx context.Context, workOrderID uuid.UUID) ([]domain.WorkOrderStatusChange, error)
+}
+
+type CustomerServicePeriodRepository interface {
+	// starts a period, a customer can only have one open
+	Open(ctx context.Context, period domain.CustomerServicePeriod) error
+	// ends the open period of the customer, nothing happens if there is none
+	Close(ctx context.Context, customerID uuid.UUID, endedAt time.Time, workOrderID uuid.UUID) error
+	// oldest first
+	FindByCustomerID(ctx context.Context, customerID uuid.UUID) ([]domain.CustomerServicePeriod, error)
+}
+
+// repositories bound to the same transaction, only valid inside UnitOfWork.Do
+type TxRepositories struct {
+	Customers     CustomerRepository
+	WorkOrders    WorkOrderRepository
+	Outbox        OutboxRepository
+	ChangeLogs    WorkOrderChangeLogRepository
+	StatusChanges WorkOrderStatusChangeRepository
+	Periods       CustomerServicePeriodRepository
+}
+
+type UnitOfWork interface {
+	// runs fn in one transaction, commits if fn returns nil and rolls back everything otherwise
+	Do(ctx context.Context, fn func(repos TxRepositories) error) error
+}
+
+// sends domain events outside the service, event is the name consumers listen to
+type EventPublisher interface {
+	Publish(ctx context.Context, event string, payload []byte) error
+}
+
+// processes one event read from the stream, an error leaves it pending so it is retried
+type EventHandler interface {
+	Handle(ctx context.Context, event string, payload []byte) error
+}
+
+// keeps the responses of requests sent with Idempotency-Key until they expire
+type IdempotencyStore interface {
+	// Reserve saves the key as in progress until key.LockedUntil and returns true, when the key
+	// is already saved, not expired and not abandoned returns the stored one and false
+	Reserve(ctx context.Context, key domain.IdempotencyKey) (*domain.IdempotencyKey, bool, error)
+	// Complete stores the response of the key Reserve returned, headers are replayed with the
+	// body. Returns false and stores nothing when the key is no longer held by it, see
+	// IdempotencyKey.HeldBy
+	Complete(ctx context.Context, reserved domain.IdempotencyKey, statusCode int, contentType string, headers map[string]string, body []byte) (bool, error)
+	// Release deletes a reserved key so the request can be sent again, used when it failed. A key
+	// another retry took over is kept
+	Release(ctx context.Context, reserved domain.IdempotencyKey) error
 }
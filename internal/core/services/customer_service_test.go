@@ -0,0 +1,292 @@
+// internal/core/services/customer_service_test.go
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+	"github.com/krud3/prueba-tecnica/internal/core/services"
+)
+
+func TestCustomerServiceServiceHistory(t *testing.T) {
+	now := time.Now()
+	// started and ended the given hours ago, ended 0 leaves it open
+	period := func(startedAgo, endedAgo int) domain.CustomerServicePeriod {
+		p := domain.CustomerServicePeriod{ID: uuid.New(), StartedAt: now.Add(-time.Duration(startedAgo) * time.Hour)}
+		if endedAgo > 0 {
+			ended := now.Add(-time.Duration(endedAgo) * time.Hour)
+			p.EndedAt = &ended
+		}
+		return p
+	}
+
+	tests := []struct {
+		name string
+		// saved in this order, the open one last, the history comes oldest first
+		periods        []domain.CustomerServicePeriod
+		wantActiveDays int
+	}{
+		{name: "never active"},
+		{name: "less than a day", periods: []domain.CustomerServicePeriod{period(23, 0)}, wantActiveDays: 0},
+		{name: "a day and a half", periods: []domain.CustomerServicePeriod{period(100, 64)}, wantActiveDays: 1},
+		// 36h and 24h closed
+		{name: "closed periods", periods: []domain.CustomerServicePeriod{period(50, 26), period(200, 164)}, wantActiveDays: 2},
+		// the 13h of the open period complete the third day
+		{name: "the open period counts until now", periods: []domain.CustomerServicePeriod{period(50, 26), period(200, 164), period(13, 0)}, wantActiveDays: 3},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTestEnv()
+			customer := env.createCustomer(t, false)
+			for _, p := range tc.periods {
+				p.CustomerID = customer.ID
+				if err := env.periods.Open(ctx, p); err != nil {
+					t.Fatalf("saving period: %v", err)
+				}
+				// Open only takes open periods, the end is set like a cancellation
+				if p.EndedAt != nil {
+					if err := env.periods.Close(ctx, customer.ID, *p.EndedAt, uuid.New()); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			history, err := env.customerService.ServiceHistory(ctx, customer.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if history.Periods == nil || len(history.Periods) != len(tc.periods) {
+				t.Fatalf("got %d periods (nil %t), want %d", len(history.Periods), history.Periods == nil, len(tc.periods))
+			}
+			for i := 1; i < len(history.Periods); i++ {
+				if history.Periods[i].StartedAt.Before(history.Periods[i-1].StartedAt) {
+					t.Fatalf("period %d starts before the previous one", i)
+				}
+			}
+			if history.ActiveDays != tc.wantActiveDays {
+				t.Fatalf("active days %d, want %d", history.ActiveDays, tc.wantActiveDays)
+			}
+		})
+	}
+
+	t.Run("unknown customer", func(t *testing.T) {
+		env := newTestEnv()
+		if _, err := env.customerService.ServiceHistory(context.Background(), uuid.New()); !errors.Is(err, services.ErrCNotFound) {
+			t.Fatalf("got %v, want %v", err, services.ErrCNotFound)
+		}
+	})
+}
+
+// completing orders opens and closes the periods of the customer
+func TestCompleteOrderRecordsPeriods(t *testing.T) {
+	tests := []struct {
+		name   string
+		active bool
+		// completed one after the other
+		orders []domain.Type
+		// for each period, true when it is still open
+		wantOpen []bool
+	}{
+		{name: "activation", orders: []domain.Type{domain.TypeActivate}, wantOpen: []bool{true}},
+		{name: "activation and cancellation", orders: []domain.Type{domain.TypeActivate, domain.TypeCancell}, wantOpen: []bool{false}},
+		{name: "activated again", orders: []domain.Type{domain.TypeActivate, domain.TypeCancell, domain.TypeActivate}, wantOpen: []bool{false, true}},
+		// active before the periods existed and without the one migration 013 backfills
+		{name: "cancellation without an open period", active: true, orders: []domain.Type{domain.TypeCancell}, wantOpen: []bool{}},
+		{name: "activated after a cancellation without period", active: true, orders: []domain.Type{domain.TypeCancell, domain.TypeActivate}, wantOpen: []bool{true}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTestEnv()
+			customer := env.createCustomer(t, tc.active)
+
+			var orderIDs []uuid.UUID
+			for _, woType := range tc.orders {
+				workOrder := env.createOrder(t, customer.ID, woType, plannedBase())
+				if _, err := env.service.CompleteOrder(ctx, workOrder.ID, "tester", nil); err != nil {
+					t.Fatalf("completing %s: %v", woType, err)
+				}
+				orderIDs = append(orderIDs, workOrder.ID)
+			}
+
+			periods, err := env.periods.FindByCustomerID(ctx, customer.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(periods) != len(tc.wantOpen) {
+				t.Fatalf("got %d periods, want %d", len(periods), len(tc.wantOpen))
+			}
+			for i, p := range periods {
+				if open := p.EndedAt == nil; open != tc.wantOpen[i] {
+					t.Fatalf("period %d open %t, want %t", i, open, tc.wantOpen[i])
+				}
+				// the order that opened it and the one that closed it
+				if p.StartWorkOrderID == nil || !slices.Contains(orderIDs, *p.StartWorkOrderID) {
+					t.Fatalf("period %d opened by %v", i, p.StartWorkOrderID)
+				}
+				if p.EndedAt != nil && (p.EndWorkOrderID == nil || !slices.Contains(orderIDs, *p.EndWorkOrderID) || p.EndedAt.Before(p.StartedAt)) {
+					t.Fatalf("period %d closed by %v at %s, started at %s", i, p.EndWorkOrderID, p.EndedAt, p.StartedAt)
+				}
+			}
+		})
+	}
+}
+
+func TestCustomerServiceUpdateDetails(t *testing.T) {
+	text := func(value string) *string { return &value }
+	current, stale := 1, 7
+
+	tests := []struct {
+		name    string
+		changes ports.CustomerChanges
+		// deletes the customer before the update
+		deleted bool
+		unknown bool
+		version *int
+		wantErr error
+		// names and address after the update
+		want [3]string
+	}{
+		{name: "first name only", changes: ports.CustomerChanges{FirstName: text("  Ana María ")}, want: [3]string{"Ana María", "Pérez", "calle 1"}},
+		{name: "every field", changes: ports.CustomerChanges{FirstName: text("Luis"), LastName: text("Gómez"), Address: text("calle 2")}, want: [3]string{"Luis", "Gómez", "calle 2"}},
+		{name: "nothing sent", want: [3]string{"Ana", "Pérez", "calle 1"}},
+		{name: "current If-Match", changes: ports.CustomerChanges{Address: text("calle 2")}, version: &current, want: [3]string{"Ana", "Pérez", "calle 2"}},
+		{name: "empty last name", changes: ports.CustomerChanges{FirstName: text("Luis"), LastName: text("")}, wantErr: services.ErrCEmpty},
+		{name: "blank address", changes: ports.CustomerChanges{Address: text("   ")}, wantErr: services.ErrCEmpty},
+		{name: "stale If-Match", changes: ports.CustomerChanges{Address: text("calle 2")}, version: &stale, wantErr: services.ErrVersionMismatch},
+		{name: "unknown customer", changes: ports.CustomerChanges{Address: text("calle 2")}, unknown: true, wantErr: services.ErrCNotFound},
+		{name: "deleted customer", changes: ports.CustomerChanges{Address: text("calle 2")}, deleted: true, wantErr: services.ErrCNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTestEnv()
+			customer := env.createCustomer(t, true)
+			if tc.deleted {
+				if err := env.customerService.Delete(ctx, customer.ID, nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+			id := customer.ID
+			if tc.unknown {
+				id = uuid.New()
+			}
+
+			updated, err := env.customerService.UpdateDetails(ctx, id, tc.changes, tc.version)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got %v, want %v", err, tc.wantErr)
+			}
+			if tc.wantErr != nil {
+				if tc.deleted || tc.unknown {
+					return
+				}
+				// nothing is saved, not even the fields that were valid
+				if stored := env.findCustomer(t, customer.ID); stored.FirstName != customer.FirstName || stored.Version != customer.Version {
+					t.Fatalf("a rejected update saved %+v", stored)
+				}
+				return
+			}
+
+			stored := env.findCustomer(t, customer.ID)
+			if got := [3]string{stored.FirstName, stored.LastName, stored.Address}; got != tc.want {
+				t.Fatalf("stored %v, want %v", got, tc.want)
+			}
+			if updated.Version != customer.Version+1 || stored.Version != updated.Version {
+				t.Fatalf("version %d returned and %d stored, want %d", updated.Version, stored.Version, customer.Version+1)
+			}
+			// the state only changes with work orders
+			if !stored.IsActive || stored.StartDate == nil {
+				t.Fatalf("the update changed the state: active %t start %v", stored.IsActive, stored.StartDate)
+			}
+		})
+	}
+}
+
+func TestCustomerServiceDelete(t *testing.T) {
+	current, stale := 1, 7
+	// completes or cancels the order after creating it, nil leaves it open
+	complete := func(env *testEnv, id uuid.UUID) error {
+		_, err := env.service.CompleteOrder(context.Background(), id, "tester", nil)
+		return err
+	}
+	cancel := func(env *testEnv, id uuid.UUID) error {
+		_, err := env.service.CancelOrder(context.Background(), id, "sin acceso", "tester", nil)
+		return err
+	}
+
+	tests := []struct {
+		name string
+		// the customer has an order, closed with close when it is set
+		withOrder bool
+		close     func(env *testEnv, id uuid.UUID) error
+		// deletes the customer before
+		deleted bool
+		unknown bool
+		version *int
+		wantErr error
+	}{
+		{name: "without orders"},
+		{name: "current If-Match", version: &current},
+		{name: "with a done order", withOrder: true, close: complete},
+		{name: "with a cancelled order", withOrder: true, close: cancel},
+		{name: "with an open order", withOrder: true, wantErr: services.ErrCOpenOrders},
+		{name: "stale If-Match", version: &stale, wantErr: services.ErrVersionMismatch},
+		{name: "unknown customer", unknown: true, wantErr: services.ErrCNotFound},
+		{name: "already deleted", deleted: true, wantErr: services.ErrCNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTestEnv()
+			customer := env.createCustomer(t, false)
+			var workOrder domain.WorkOrder
+			if tc.withOrder {
+				workOrder = env.createOrder(t, customer.ID, domain.TypeActivate, plannedBase())
+				if tc.close != nil {
+					if err := tc.close(env, workOrder.ID); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			if tc.deleted {
+				if err := env.customerService.Delete(ctx, customer.ID, nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+			id := customer.ID
+			if tc.unknown {
+				id = uuid.New()
+			}
+
+			err := env.customerService.Delete(ctx, id, tc.version)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got %v, want %v", err, tc.wantErr)
+			}
+
+			_, findErr := env.customerService.FindByID(ctx, customer.ID)
+			if tc.wantErr != nil && !tc.deleted {
+				if findErr != nil {
+					t.Fatalf("a rejected delete removed the customer: %v", findErr)
+				}
+				return
+			}
+			if !errors.Is(findErr, services.ErrCNotFound) {
+				t.Fatalf("deleted customer found, err %v", findErr)
+			}
+			// the orders stay for the reports
+			if tc.withOrder {
+				if _, err := env.workOrders.FindByID(ctx, workOrder.ID); err != nil {
+					t.Fatalf("the order of the deleted customer: %v", err)
+				}
+			}
+		})
+	}
+}
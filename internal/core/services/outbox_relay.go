@@ -0,0 +1,95 @@
+// internal/core/services/outbox_relay.go
+
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+)
+
+// OutboxRelay reads pending outbox messages and sends them with the publisher, a message is
+// only marked as published after the publisher accepted it so it can be sent more than once
+// but never lost. Each batch is claimed in a unit of work, so several relays can run against
+// postgres without sending the same message twice
+type OutboxRelay struct {
+	uow       ports.UnitOfWork
+	publisher ports.EventPublisher
+	interval  time.Duration
+	batchSize int
+}
+
+func NewOutboxRelay(uow ports.UnitOfWork, publisher ports.EventPublisher, interval time.Duration, batchSize int) *OutboxRelay {
+	return &OutboxRelay{
+		uow:       uow,
+		publisher: publisher,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+// Run drains the outbox every interval until ctx is cancelled, failed messages are retried
+// on the next tick
+func (oR *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(oR.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := oR.Drain(ctx); err != nil {
+			log.Printf("Error enviando eventos del outbox: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Drain sends pending messages until the outbox is empty, the publisher fails or an older
+// message is being sent by another relay, returns how many were published
+func (oR *OutboxRelay) Drain(ctx context.Context) (int, error) {
+	published := 0
+
+	for {
+		claimed, sent := 0, 0
+		var publishErr error
+
+		err := oR.uow.Do(ctx, func(repos ports.TxRepositories) error {
+			messages, err := repos.Outbox.FindPending(ctx, oR.batchSize)
+			if err != nil {
+				return err
+			}
+			claimed = len(messages)
+
+			for _, message := range messages {
+				if err := oR.publisher.Publish(ctx, message.Event, []byte(message.Payload)); err != nil {
+					// keep the attempt and stop, next messages must not overtake this one. The
+					// error is returned after the commit, the messages already sent stay marked
+					publishErr = err
+					return repos.Outbox.MarkFailed(ctx, message.ID, err.Error())
+				}
+
+				if err := repos.Outbox.MarkPublished(ctx, message.ID); err != nil {
+					return err
+				}
+				sent++
+			}
+			return nil
+		})
+		if err != nil {
+			return published, err
+		}
+		published += sent
+		if publishErr != nil {
+			return published, publishErr
+		}
+		// nothing left
+		if claimed == 0 {
+			return published, nil
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// internal/core/services/outbox_relay_test.go
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/krud3/prueba-tecnica/internal/adapters/memory"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/services"
+)
+
+// records the events but fails the calls of failAt, counted from 1
+type failingPublisher struct {
+	*memory.RecordingPublisher
+	failAt []int
+	calls  int
+}
+
+func (p *failingPublisher) Publish(ctx context.Context, event string, payload []byte) error {
+	p.calls++
+	if slices.Contains(p.failAt, p.calls) {
+		return errors.New("redis caído")
+	}
+	return p.RecordingPublisher.Publish(ctx, event, payload)
+}
+
+func TestOutboxRelayDrain(t *testing.T) {
+	tests := []struct {
+		name      string
+		messages  int
+		batchSize int
+		failAt    []int
+		// published by the first drain, the second one sends the rest
+		wantFirst int
+		wantErr   bool
+	}{
+		{name: "empty outbox", batchSize: 2},
+		{name: "several batches", messages: 5, batchSize: 2, wantFirst: 5},
+		{name: "fails in the first batch", messages: 3, batchSize: 5, failAt: []int{2}, wantFirst: 1, wantErr: true},
+		// the batch already sent stays published
+		{name: "fails in the second batch", messages: 5, batchSize: 2, failAt: []int{4}, wantFirst: 3, wantErr: true},
+		{name: "fails the first message", messages: 2, batchSize: 2, failAt: []int{1}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := memory.NewDB()
+			outbox := memory.NewMemoryOutboxRepository(db)
+			var want []string
+			for i := 0; i < tc.messages; i++ {
+				event := fmt.Sprintf("event_%d", i)
+				if err := outbox.Create(ctx, domain.OutboxMessage{Event: event, Payload: "{}"}); err != nil {
+					t.Fatal(err)
+				}
+				want = append(want, event)
+			}
+			publisher := &failingPublisher{RecordingPublisher: memory.NewRecordingPublisher(), failAt: tc.failAt}
+			relay := services.NewOutboxRelay(memory.NewMemoryUnitOfWork(db), publisher, time.Second, tc.batchSize)
+
+			published, err := relay.Drain(ctx)
+			if (err != nil) != tc.wantErr || published != tc.wantFirst {
+				t.Fatalf("published %d with error %v, want %d and error %t", published, err, tc.wantFirst, tc.wantErr)
+			}
+			pending, err := outbox.FindPending(ctx, tc.messages+1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pending) != tc.messages-tc.wantFirst {
+				t.Fatalf("%d pending, want %d", len(pending), tc.messages-tc.wantFirst)
+			}
+			// the failed attempt is kept even if the error stopped the drain
+			if tc.wantErr && (pending[0].Attempts != 1 || pending[0].LastError == "") {
+				t.Fatalf("failed message %+v, want one attempt and its error", pending[0])
+			}
+
+			// the next drain sends the rest, in order and without repeating
+			if _, err := relay.Drain(ctx); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, event := range publisher.Events() {
+				got = append(got, event.Event)
+			}
+			if !slices.Equal(got, want) {
+				t.Fatalf("published %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+// internal/core/services/policy.go
+
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+)
+
+// names of the rules, the same keys the config file uses, see config.RulesConfig
+const (
+	RuleMaxDuration  = "max_duration"
+	RuleMinLeadTime  = "min_lead_time"
+	RuleWorkingHours = "working_hours"
+)
+
+// Policy has the scheduling rules of work orders, it is loaded at startup so each region can
+// run with its own limits
+type Policy struct {
+	// longest planned window of an order
+	MaxDuration time.Duration
+	// time between now and the planned begin, 0 only forbids the past
+	MinLeadTime time.Duration
+	// nil means any hour of the day
+	WorkingHours *WorkingHours
+}
+
+// hours of the day an order can be planned in, Start and End are the time since midnight in
+// Location
+type WorkingHours struct {
+	Start    time.Duration
+	End      time.Duration
+	Location *time.Location
+}
+
+// DefaultPolicy is the original rule of the business logic, windows of two hours at most
+func DefaultPolicy() Policy {
+	return Policy{MaxDuration: 2 * time.Hour}
+}
+
+// PolicyConfig is the policy as text, durations like 2h or 30m and working hours like
+// 08:00-18:00. Empty fields keep the default
+type PolicyConfig struct {
+	MaxDuration  string
+	MinLeadTime  string
+	WorkingHours string
+	// IANA name of the zone of the working hours, UTC if empty
+	Timezone string
+}
+
+// Policy validates the configuration and builds the policy from it
+func (c PolicyConfig) Policy() (Policy, error) {
+	policy := DefaultPolicy()
+
+	if c.MaxDuration != "" {
+		duration, err := time.ParseDuration(c.MaxDuration)
+		if err != nil || duration <= 0 {
+			return Policy{}, fmt.Errorf("%s inválido: %q, debe ser una duración mayor a 0 como 2h", RuleMaxDuration, c.MaxDuration)
+		}
+		policy.MaxDuration = duration
+	}
+
+	if c.MinLeadTime != "" {
+		duration, err := time.ParseDuration(c.MinLeadTime)
+		if err != nil || duration < 0 {
+			return Policy{}, fmt.Errorf("%s inválido: %q, debe ser una duración como 30m", RuleMinLeadTime, c.MinLeadTime)
+		}
+		policy.MinLeadTime = duration
+	}
+
+	if c.WorkingHours != "" {
+		workingHours, err := parseWorkingHours(c.WorkingHours, c.Timezone)
+		if err != nil {
+			return Policy{}, err
+		}
+		policy.WorkingHours = workingHours
+	} else if c.Timezone != "" {
+		// unused without working hours, but a typo must stop the startup too
+		if _, err := loadLocation(c.Timezone); err != nil {
+			return Policy{}, err
+		}
+	}
+	return policy, nil
+}
+
+// reads 08:00-18:00, start goes before end in the same day
+func parseWorkingHours(value, timezone string) (*WorkingHours, error) {
+	invalid := fmt.Errorf("%s inválido: %q, debe tener la forma 08:00-18:00", RuleWorkingHours, value)
+
+	startStr, endStr, ok := strings.Cut(value, "-")
+	if !ok {
+		return nil, invalid
+	}
+	start, err := time.Parse("15:04", strings.TrimSpace(startStr))
+	if err != nil {
+		return nil, invalid
+	}
+	end, err := time.Parse("15:04", strings.TrimSpace(endStr))
+	if err != nil || !end.After(start) {
+		return nil, invalid
+	}
+
+	location, err := loadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	// time.Parse gives the hour on year 0, the time since midnight is what matters
+	midnight := time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)
+	return &WorkingHours{
+		Start:    start.Sub(midnight),
+		End:      end.Sub(midnight),
+		Location: location,
+	}, nil
+}
+
+// UTC when empty
+func loadLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("timezone inválido: %q", timezone)
+	}
+	return location, nil
+}
+
+// checks the planned window alone, end after begin, the max duration and the working hours
+func (p Policy) checkWindow(begin, end time.Time) error {
+	if !end.After(begin) {
+		return ErrDateOrder
+	}
+	if end.Sub(begin) > p.MaxDuration {
+		limit := formatDuration(p.MaxDuration)
+		return domain.NewRuleError("planned_window_too_long", "la diferencia entre las fechas de planeación no debe ser mayor a "+limit, RuleMaxDuration, limit)
+	}
+	if p.WorkingHours != nil && !p.WorkingHours.contains(begin, end) {
+		limit := p.WorkingHours.String()
+		return domain.NewRuleError("planned_outside_working_hours", "las fechas planeadas deben estar dentro del horario laboral "+limit, RuleWorkingHours, limit)
+	}
+	return nil
+}
+
+// checks the planned begin against now, it can not be in the past nor before the lead time
+func (p Policy) checkLeadTime(begin, now time.Time) error {
+	if begin.Before(now) {
+		return ErrDatePast
+	}
+	if begin.Before(now.Add(p.MinLeadTime)) {
+		limit := formatDuration(p.MinLeadTime)
+		return domain.NewRuleError("planned_date_too_soon", "la fecha de inicio planeada debe ser al menos "+limit+" después de ahora", RuleMinLeadTime, limit)
+	}
+	return nil
+}
+
+// begin and end in the same day and inside the working hours of that day
+func (w WorkingHours) contains(begin, end time.Time) bool {
+	begin, end = begin.In(w.Location), end.In(w.Location)
+	year, month, day := begin.Date()
+	if endYear, endMonth, endDay := end.Date(); endYear != year || endMonth != month || endDay != day {
+		return false
+	}
+	return clockOf(begin) >= w.Start && clockOf(end) <= w.End
+}
+
+// hour of the day read on the clock, not the time since midnight that is one hour more or less
+// on the days the clock moves for DST
+func clockOf(t time.Time) time.Duration {
+	hour, minute, second := t.Clock()
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute + time.Duration(second)*time.Second + time.Duration(t.Nanosecond())
+}
+
+// ej. 08:00-18:00 America/Bogota
+func (w WorkingHours) String() string {
+	clock := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return clock(w.Start) + "-" + clock(w.End) + " " + w.Location.String()
+}
+
+// 2h instead of 2h0m0s
+func formatDuration(d time.Duration) string {
+	text := d.String()
+	if strings.HasSuffix(text, "m0s") {
+		text = strings.TrimSuffix(text, "0s")
+	}
+	if strings.HasSuffix(text, "h0m") {
+		text = strings.TrimSuffix(text, "0m")
+	}
+	return text
+}
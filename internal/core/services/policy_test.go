@@ -0,0 +1,251 @@
+// internal/core/services/policy_test.go
+
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("zona %s no disponible: %v", name, err)
+	}
+	return location
+}
+
+// the code and the limit of a rule error, or the sentinel itself
+func sameRule(err, want error) bool {
+	if errors.Is(err, want) {
+		return true
+	}
+	var got, expected *domain.Error
+	return errors.As(err, &got) && errors.As(want, &expected) && got.Code == expected.Code && got.Rule == expected.Rule && got.Limit == expected.Limit
+}
+
+func TestPolicyConfigPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		config PolicyConfig
+		// only the fields of the policy without working hours, see TestParseWorkingHours
+		want      Policy
+		wantHours string
+		wantErr   string
+	}{
+		{name: "empty keeps the default", want: DefaultPolicy()},
+		{name: "max duration", config: PolicyConfig{MaxDuration: "90m"}, want: Policy{MaxDuration: 90 * time.Minute}},
+		{name: "zero max duration", config: PolicyConfig{MaxDuration: "0"}, wantErr: RuleMaxDuration},
+		{name: "negative max duration", config: PolicyConfig{MaxDuration: "-1h"}, wantErr: RuleMaxDuration},
+		{name: "max duration without unit", config: PolicyConfig{MaxDuration: "2"}, wantErr: RuleMaxDuration},
+		{name: "lead time", config: PolicyConfig{MinLeadTime: "30m"}, want: Policy{MaxDuration: 2 * time.Hour, MinLeadTime: 30 * time.Minute}},
+		{name: "zero lead time", config: PolicyConfig{MinLeadTime: "0"}, want: DefaultPolicy()},
+		{name: "negative lead time", config: PolicyConfig{MinLeadTime: "-5m"}, wantErr: RuleMinLeadTime},
+		{
+			name:      "working hours in a timezone",
+			config:    PolicyConfig{WorkingHours: "08:00-18:00", Timezone: "America/Bogota"},
+			want:      DefaultPolicy(),
+			wantHours: "08:00-18:00 America/Bogota",
+		},
+		{name: "working hours in UTC", config: PolicyConfig{WorkingHours: "08:00-18:00"}, want: DefaultPolicy(), wantHours: "08:00-18:00 UTC"},
+		{name: "invalid working hours", config: PolicyConfig{WorkingHours: "8 a 18"}, wantErr: RuleWorkingHours},
+		{name: "bad timezone", config: PolicyConfig{WorkingHours: "08:00-18:00", Timezone: "America/Bogotá"}, wantErr: "timezone"},
+		{name: "bad timezone without working hours", config: PolicyConfig{Timezone: "Mars/Olympus"}, wantErr: "timezone"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			policy, err := tc.config.Policy()
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("got %v, want an error about %s", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			hours := ""
+			if policy.WorkingHours != nil {
+				hours = policy.WorkingHours.String()
+			}
+			if hours != tc.wantHours {
+				t.Fatalf("working hours %q, want %q", hours, tc.wantHours)
+			}
+			policy.WorkingHours = nil
+			if policy != tc.want {
+				t.Fatalf("policy %+v, want %+v", policy, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseWorkingHours(t *testing.T) {
+	tests := []struct {
+		value     string
+		timezone  string
+		wantStart time.Duration
+		wantEnd   time.Duration
+		wantZone  string
+		wantErr   bool
+	}{
+		{value: "08:00-18:00", wantStart: 8 * time.Hour, wantEnd: 18 * time.Hour, wantZone: "UTC"},
+		{value: " 07:30 - 16:45 ", timezone: "America/Bogota", wantStart: 7*time.Hour + 30*time.Minute, wantEnd: 16*time.Hour + 45*time.Minute, wantZone: "America/Bogota"},
+		{value: "00:00-23:59", wantStart: 0, wantEnd: 23*time.Hour + 59*time.Minute, wantZone: "UTC"},
+		// a night shift crosses midnight, the end would be the next day
+		{value: "22:00-06:00", wantErr: true},
+		{value: "08:00-08:00", wantErr: true},
+		{value: "08:00", wantErr: true},
+		{value: "8-18", wantErr: true},
+		{value: "08:00-24:00", wantErr: true},
+		{value: "08:00-18:00", timezone: "Mars/Olympus", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.value+" "+tc.timezone, func(t *testing.T) {
+			workingHours, err := parseWorkingHours(tc.value, tc.timezone)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("parsed %+v, want an error", workingHours)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if workingHours.Start != tc.wantStart || workingHours.End != tc.wantEnd || workingHours.Location.String() != tc.wantZone {
+				t.Fatalf("got %s, want %s-%s %s", workingHours, tc.wantStart, tc.wantEnd, tc.wantZone)
+			}
+		})
+	}
+}
+
+func TestWorkingHoursContains(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	workingHours := WorkingHours{Start: 8 * time.Hour, End: 18 * time.Hour, Location: newYork}
+	at := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, newYork)
+	}
+
+	tests := []struct {
+		name       string
+		begin, end time.Time
+		want       bool
+	}{
+		{"inside", at(2025, 6, 10, 9, 0), at(2025, 6, 10, 11, 0), true},
+		{"the whole day", at(2025, 6, 10, 8, 0), at(2025, 6, 10, 18, 0), true},
+		{"begins before the start", at(2025, 6, 10, 7, 59), at(2025, 6, 10, 9, 0), false},
+		{"ends after the end", at(2025, 6, 10, 17, 0), at(2025, 6, 10, 18, 1), false},
+		{"crosses midnight", at(2025, 6, 10, 23, 0), at(2025, 6, 11, 1, 0), false},
+		// 13:00-14:00 UTC is 09:00-10:00 in New York in summer
+		{"given in another zone", time.Date(2025, 6, 10, 13, 0, 0, 0, time.UTC), time.Date(2025, 6, 10, 14, 0, 0, 0, time.UTC), true},
+		{"outside once in the zone", time.Date(2025, 6, 10, 9, 0, 0, 0, time.UTC), time.Date(2025, 6, 10, 10, 0, 0, 0, time.UTC), false},
+		// the clock jumps from 02:00 to 03:00, 08:30 is 7h30m after midnight
+		{"day the clock moves forward", at(2025, 3, 9, 8, 30), at(2025, 3, 9, 9, 30), true},
+		{"before the start the day the clock moves forward", at(2025, 3, 9, 7, 30), at(2025, 3, 9, 8, 30), false},
+		// the clock goes back from 02:00 to 01:00, 18:00 is 19h after midnight
+		{"day the clock moves back", at(2025, 11, 2, 17, 0), at(2025, 11, 2, 18, 0), true},
+		{"after the end the day the clock moves back", at(2025, 11, 2, 17, 30), at(2025, 11, 2, 18, 30), false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := workingHours.contains(tc.begin, tc.end); got != tc.want {
+				t.Fatalf("contains(%s, %s) = %t, want %t", tc.begin, tc.end, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCheckWindow(t *testing.T) {
+	bogota := mustLoadLocation(t, "America/Bogota")
+	open := DefaultPolicy()
+	office := Policy{MaxDuration: 3 * time.Hour, WorkingHours: &WorkingHours{Start: 8 * time.Hour, End: 18 * time.Hour, Location: bogota}}
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, 6, day, hour, minute, 0, 0, bogota)
+	}
+	tooLong := func(limit string) error {
+		return domain.NewRuleError("planned_window_too_long", "", RuleMaxDuration, limit)
+	}
+	outside := domain.NewRuleError("planned_outside_working_hours", "", RuleWorkingHours, "08:00-18:00 America/Bogota")
+
+	tests := []struct {
+		name       string
+		policy     Policy
+		begin, end time.Time
+		wantErr    error
+	}{
+		{"inside the max duration", open, at(10, 9, 0), at(10, 10, 0), nil},
+		{"exactly the max duration", open, at(10, 9, 0), at(10, 11, 0), nil},
+		{"longer than the max duration", open, at(10, 9, 0), at(10, 11, 1), tooLong("2h")},
+		{"limit of the policy", office, at(10, 9, 0), at(10, 12, 30), tooLong("3h")},
+		{"same begin and end", open, at(10, 9, 0), at(10, 9, 0), ErrDateOrder},
+		{"end before begin", open, at(10, 10, 0), at(10, 9, 0), ErrDateOrder},
+		{"crosses midnight without working hours", open, at(10, 23, 0), at(11, 1, 0), nil},
+		{"crosses midnight with working hours", office, at(10, 23, 0), at(11, 1, 0), outside},
+		{"inside the working hours", office, at(10, 15, 0), at(10, 18, 0), nil},
+		{"outside the working hours", office, at(10, 6, 0), at(10, 8, 0), outside},
+		// 17:00-18:00 in Bogota is 22:00-23:00 UTC
+		{"given in UTC", office, time.Date(2025, 6, 10, 22, 0, 0, 0, time.UTC), time.Date(2025, 6, 10, 23, 0, 0, 0, time.UTC), nil},
+		// the max duration is checked first
+		{"too long and outside", office, at(10, 5, 0), at(10, 9, 0), tooLong("3h")},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.policy.checkWindow(tc.begin, tc.end); !sameRule(err, tc.wantErr) {
+				t.Fatalf("got %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckLeadTime(t *testing.T) {
+	now := time.Date(2025, 6, 10, 9, 0, 0, 0, time.UTC)
+	withLead := Policy{MaxDuration: 2 * time.Hour, MinLeadTime: 90 * time.Minute}
+	tooSoon := domain.NewRuleError("planned_date_too_soon", "", RuleMinLeadTime, "1h30m")
+
+	tests := []struct {
+		name    string
+		policy  Policy
+		begin   time.Time
+		wantErr error
+	}{
+		{"now without lead time", DefaultPolicy(), now, nil},
+		{"in the past", DefaultPolicy(), now.Add(-time.Nanosecond), ErrDatePast},
+		{"later", DefaultPolicy(), now.Add(time.Minute), nil},
+		{"in the past with lead time", withLead, now.Add(-time.Hour), ErrDatePast},
+		{"before the lead time", withLead, now.Add(89 * time.Minute), tooSoon},
+		{"exactly the lead time", withLead, now.Add(90 * time.Minute), nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.policy.checkLeadTime(tc.begin, now); !sameRule(err, tc.wantErr) {
+				t.Fatalf("got %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		want     string
+	}{
+		{2 * time.Hour, "2h"},
+		{90 * time.Minute, "1h30m"},
+		{30 * time.Minute, "30m"},
+		{45 * time.Second, "45s"},
+		{2*time.Hour + 30*time.Second, "2h0m30s"},
+		{0, "0s"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.want, func(t *testing.T) {
+			if got := formatDuration(tc.duration); got != tc.want {
+				t.Fatalf("formatDuration(%s) = %q, want %q", tc.duration, got, tc.want)
+			}
+		})
+	}
+}
@@ -4,193 +4,731 @@ package services
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
+	"strings"
 	"time"
 
-	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
 	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/events"
 	"github.com/krud3/prueba-tecnica/internal/core/ports"
 )
 
 var (
 	// handle error for active customers that recieve active orders
-	ErrAA = errors.New("el cliente ya tiene su estado activado")
+	ErrAA = domain.NewConflictError("customer_already_active", "el cliente ya tiene su estado activado")
 
 	// handle error for no active customers that recieve cancel order
-	ErrCC = errors.New("el cliente ya tiene su estado cancelado")
+	ErrCC = domain.NewConflictError("customer_already_cancelled", "el cliente ya tiene su estado cancelado")
 
-	// handle error for planned date interval
-	ErrDateIntertal = errors.New("la diferencia entre las fechas de planeación no debe ser mayor a dos horas")
+	// handle error for planned end before or equal to planned begin
+	ErrDateOrder = domain.NewValidationError("planned_dates_out_of_order", "la fecha de fin planeada debe ser posterior a la fecha de inicio")
+
+	// handle error for planned begin that already passed
+	ErrDatePast = domain.NewValidationError("planned_date_in_past", "la fecha de inicio planeada no puede estar en el pasado")
+
+	// handle error for workOrder type that is not part of the business logic
+	ErrWOType = domain.NewValidationError("work_order_type_invalid", "el tipo de la orden debe ser 'activar cliente' o 'cancelar cliente'")
+
+	// handle error for a second open workOrder of the same type for a customer
+	ErrWOOpen = domain.NewConflictError("work_order_already_open", "el cliente ya tiene una orden abierta de este tipo, complétela o cancélela antes de crear otra")
+
+	// handle error for a workOrder planned at the same time as another open one of the customer
+	ErrWOOverlap = domain.ErrScheduleOverlap
 
 	// handle error for workOrder done trying to be completed
-	ErrWODone = errors.New("la orden ya estaba completada y está intentando completarla")
+	ErrWODone = domain.NewConflictError("work_order_already_done", "la orden ya estaba completada y está intentando completarla")
 
 	// handle error for workOrder canceled trying to be completed
-	ErrWOCancelled = errors.New("la orden está cancelada y está intentando completarla")
+	ErrWOCancelled = domain.NewConflictError("work_order_cancelled", "la orden está cancelada y está intentando completarla")
+
+	// handle error for workOrder done trying to be cancelled
+	ErrWODoneCancel = domain.NewConflictError("work_order_already_done", "la orden ya estaba completada y no se puede cancelar")
+
+	// handle error for workOrder cancelled trying to be cancelled again
+	ErrWOCancelledCancel = domain.NewConflictError("work_order_already_cancelled", "la orden ya estaba cancelada y está intentando cancelarla")
+
+	// handle error for cancellation without reason
+	ErrNoReason = domain.NewValidationError("cancellation_reason_required", "debe indicar el motivo de la cancelación")
+
+	// handle error for workOrder done trying to be edited
+	ErrWODoneEdit = domain.NewConflictError("work_order_already_done", "la orden ya estaba completada y no se puede modificar")
+
+	// handle error for workOrder cancelled trying to be edited
+	ErrWOCancelledEdit = domain.NewConflictError("work_order_cancelled", "la orden está cancelada y no se puede modificar")
+
+	// handle error for workOrder description sent empty
+	ErrWOEmpty = domain.NewValidationError("work_order_description_empty", "la descripción de la orden no puede estar vacía")
+
+	// handle error for workOrder that does not exist, repositories return it
+	ErrWONotFound = domain.ErrWorkOrderNotFound
+
+	// handle error for customer that does not exist or was deleted, repositories return it
+	ErrCNotFound = domain.ErrCustomerNotFound
+
+	// handle error for If-Match with a version that is not the current one
+	ErrVersionMismatch = domain.NewPreconditionError("version_mismatch", "la versión enviada en If-Match no es la versión actual del registro")
+
+	// handle error for customer names or address sent empty
+	ErrCEmpty = domain.NewValidationError("customer_field_empty", "los nombres y la dirección del cliente no pueden estar vacíos")
+
+	// handle error for deleting a customer with open workOrders, completing them later would fail
+	ErrCOpenOrders = domain.NewConflictError("customer_has_open_work_orders", "el cliente tiene órdenes de trabajo abiertas, complételas o cancélelas antes de eliminarlo")
 )
 
 type CustomerService struct {
 	cRepo ports.CustomerRepository
+	uow   ports.UnitOfWork
 }
 
-func NewCustomerService(customerRepo ports.CustomerRepository) *CustomerService {
-	return &CustomerService{cRepo: customerRepo}
+// uow writes the customer and its events in the same transaction
+func NewCustomerService(customerRepo ports.CustomerRepository, uow ports.UnitOfWork) *CustomerService {
+	return &CustomerService{cRepo: customerRepo, uow: uow}
 }
 
-func (cS *CustomerService) Create(ctx context.Context, customer domain.Customer) error {
-	return cS.cRepo.Create(ctx, customer)
+// Create fills the id, version and creation date of customer
+func (cS *CustomerService) Create(ctx context.Context, customer *domain.Customer) error {
+	// names and address are mandatory, same rule as UpdateDetails
+	if strings.TrimSpace(customer.FirstName) == "" || strings.TrimSpace(customer.LastName) == "" || strings.TrimSpace(customer.Address) == "" {
+		return ErrCEmpty
+	}
+	// the id goes in the event, can not wait for the repository
+	if customer.ID == uuid.Nil {
+		customer.ID = uuid.New()
+	}
+	customer.Version = 1
+	customer.CreatedAt = time.Now()
+
+	return cS.uow.Do(ctx, func(repos ports.TxRepositories) error {
+		if err := repos.Customers.Create(ctx, *customer); err != nil {
+			return err
+		}
+		// event stored in the outbox, OutboxRelay sends it to the stream
+		message, err := outboxMessage(events.CustomerCreated, customer.ID, events.NewCustomerPayload(*customer))
+		if err != nil {
+			return err
+		}
+		return repos.Outbox.Create(ctx, message)
+	})
 }
 
 func (cS *CustomerService) FindByID(ctx context.Context, id uuid.UUID) (*domain.Customer, error) {
 	return cS.cRepo.FindByID(ctx, id)
 }
 
-func (cS *CustomerService) GetActive(ctx context.Context) ([]domain.Customer, error) {
-	return cS.cRepo.GetActive(ctx)
+func (cS *CustomerService) GetActive(ctx context.Context, pagination ports.Pagination) (ports.Page[domain.Customer], error) {
+	return cS.cRepo.GetActive(ctx, pagination)
 }
 
-func (cS *CustomerService) GetAll(ctx context.Context) ([]domain.Customer, error) {
-	return cS.cRepo.GetAll(ctx)
+func (cS *CustomerService) GetAll(ctx context.Context, pagination ports.Pagination) (ports.Page[domain.Customer], error) {
+	return cS.cRepo.GetAll(ctx, pagination)
 }
 
 func (cS *CustomerService) Update(ctx context.Context, customer domain.Customer) error {
 	return cS.cRepo.Update(ctx, customer)
 }
 
+// changes only the fields given, the state of the customer is left to work orders. version is
+// the one the client expects, nil to skip the check
+func (cS *CustomerService) UpdateDetails(ctx context.Context, id uuid.UUID, changes ports.CustomerChanges, version *int) (*domain.Customer, error) {
+	customer, err := cS.cRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(version, customer.Version); err != nil {
+		return nil, err
+	}
+
+	// apply only what was sent, an empty value is not allowed
+	fields := []struct {
+		value  *string
+		target *string
+	}{
+		{changes.FirstName, &customer.FirstName},
+		{changes.LastName, &customer.LastName},
+		{changes.Address, &customer.Address},
+	}
+	for _, field := range fields {
+		if field.value == nil {
+			continue
+		}
+		value := strings.TrimSpace(*field.value)
+		if value == "" {
+			return nil, ErrCEmpty
+		}
+		*field.target = value
+	}
+
+	if err := cS.cRepo.Update(ctx, *customer); err != nil {
+		return nil, err
+	}
+	// the repository moved the stored version
+	customer.Version++
+	return customer, nil
+}
+
+// soft deletes the customer, it disappears from every list. Its done and cancelled orders are
+// kept, while it has open ones it can not be deleted
+func (cS *CustomerService) Delete(ctx context.Context, id uuid.UUID, version *int) error {
+	return cS.uow.Do(ctx, func(repos ports.TxRepositories) error {
+		// not found if it does not exist or was already deleted
+		customer, err := repos.Customers.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(version, customer.Version); err != nil {
+			return err
+		}
+
+		status := domain.StatusNew
+		open, err := repos.WorkOrders.FindByFilter(ctx, ports.WorkOrderFilters{
+			CustomerID: &id,
+			Status:     &status,
+			Pagination: ports.Pagination{Limit: 1},
+		})
+		if err != nil {
+			return err
+		}
+		if open.Total > 0 {
+			return ErrCOpenOrders
+		}
+
+		// the version that was read, a change made after the check is not deleted
+		return repos.Customers.Delete(ctx, id, customer.Version)
+	})
+}
+
+// every period the customer was active, oldest first, and the days adding all of them. The
+// open period counts until now
+func (cS *CustomerService) ServiceHistory(ctx context.Context, id uuid.UUID) (ports.CustomerServiceHistory, error) {
+	history := ports.CustomerServiceHistory{Periods: []domain.CustomerServicePeriod{}}
+
+	err := cS.uow.Do(ctx, func(repos ports.TxRepositories) error {
+		// 404 for customers that do not exist instead of an empty history
+		if _, err := repos.Customers.FindByID(ctx, id); err != nil {
+			return err
+		}
+		periods, err := repos.Periods.FindByCustomerID(ctx, id)
+		if err != nil {
+			return err
+		}
+		history.Periods = periods
+		return nil
+	})
+	if err != nil {
+		return ports.CustomerServiceHistory{}, err
+	}
+
+	now := time.Now()
+	var active time.Duration
+	for _, period := range history.Periods {
+		active += period.Duration(now)
+	}
+	// only whole days
+	history.ActiveDays = int(active / (24 * time.Hour))
+	return history, nil
+}
+
 type WorkOrderService struct {
-	wRepo      ports.WorkOrderRepository
-	cRepo      ports.CustomerRepository
-	redis      *redis.Client
-	streamName string
+	wRepo  ports.WorkOrderRepository
+	cRepo  ports.CustomerRepository
+	uow    ports.UnitOfWork
+	policy Policy
 }
 
-func NewWorkOrderService(workOrderRepo ports.WorkOrderRepository, customerRepo ports.CustomerRepository, redisClient *redis.Client, stream string) *WorkOrderService {
+// events are not published here, they go to the outbox and OutboxRelay sends them. policy has
+// the scheduling rules, DefaultPolicy if there is no configuration
+func NewWorkOrderService(workOrderRepo ports.WorkOrderRepository, customerRepo ports.CustomerRepository, uow ports.UnitOfWork, policy Policy) *WorkOrderService {
 	return &WorkOrderService{
-		wRepo:      workOrderRepo,
-		cRepo:      customerRepo,
-		redis:      redisClient,
-		streamName: stream,
+		wRepo:  workOrderRepo,
+		cRepo:  customerRepo,
+		uow:    uow,
+		policy: policy,
 	}
 }
 
-func (wS *WorkOrderService) Create(ctx context.Context, workOrder domain.WorkOrder) error {
-
-	// compares end and begin not > 2 #business logic 2
-	if workOrder.PlannedDateEnd.Sub(workOrder.PlannedDateBegin).Hours() > 2 {
-		return ErrDateIntertal
+// Create fills the id, status, version and creation date of workOrder, actor is who created it
+func (wS *WorkOrderService) Create(ctx context.Context, workOrder *domain.WorkOrder, actor string) error {
+	// only activate or cancel
+	if !workOrder.Type.IsValid() {
+		return ErrWOType
+	}
+	if strings.TrimSpace(workOrder.Description) == "" {
+		return ErrWOEmpty
 	}
 
-	// get customer
-	customer, err := wS.cRepo.FindByID(ctx, workOrder.CustomerID)
-	if err != nil {
+	// compares end and begin with the configured rules #business logic 2
+	if err := wS.policy.checkWindow(workOrder.PlannedDateBegin, workOrder.PlannedDateEnd); err != nil {
+		return err
+	}
+	// an order can not be planned for something that already passed or is too close
+	if err := wS.policy.checkLeadTime(workOrder.PlannedDateBegin, time.Now()); err != nil {
 		return err
 	}
 
-	// switch customer.IsActive from domain to handle errors
-	switch customer.IsActive {
-	case true:
-		// trying to activate a customer already active
-		if workOrder.Type == domain.TypeActivate {
-			return ErrAA
+	// the id and status go in the event, can not wait for the repository
+	if workOrder.ID == uuid.Nil {
+		workOrder.ID = uuid.New()
+	}
+	workOrder.Status = domain.StatusNew
+	workOrder.Version = 1
+	workOrder.CreatedAt = time.Now()
+
+	return wS.uow.Do(ctx, func(repos ports.TxRepositories) error {
+		// get customer
+		customer, err := repos.Customers.FindByID(ctx, workOrder.CustomerID)
+		if err != nil {
+			return err
 		}
-	case false:
-		// trying to cancel a customer notActive
-		if workOrder.Type == domain.TypeCancell {
-			return ErrCC
+
+		if err := checkTransition(*customer, workOrder.Type); err != nil {
+			return err
+		}
+
+		// one open order of each type per customer, completing several in a row would
+		// activate or cancel the customer again
+		status := domain.StatusNew
+		open, err := repos.WorkOrders.FindByFilter(ctx, ports.WorkOrderFilters{
+			CustomerID: &workOrder.CustomerID,
+			Type:       &workOrder.Type,
+			Status:     &status,
+			Pagination: ports.Pagination{Limit: 1},
+		})
+		if err != nil {
+			return err
+		}
+		if open.Total > 0 {
+			return ErrWOOpen
+		}
+		if err := checkOverlap(ctx, repos.WorkOrders, *workOrder); err != nil {
+			return err
 		}
-	}
 
-	// create workOrder
-	return wS.wRepo.Create(ctx, workOrder)
+		// create workOrder, the unique index catches two requests creating at the same time
+		if err := repos.WorkOrders.Create(ctx, *workOrder); err != nil {
+			if errors.Is(err, domain.ErrDuplicated) {
+				return ErrWOOpen
+			}
+			return err
+		}
+		// first step of the history, there is no status before
+		if err := recordStatus(ctx, repos, *workOrder, nil, actor); err != nil {
+			return err
+		}
+		// event stored in the outbox, OutboxRelay sends it to the stream
+		message, err := outboxMessage(events.WorkOrderCreated, workOrder.ID, events.NewWorkOrderPayload(*workOrder))
+		if err != nil {
+			return err
+		}
+		return repos.Outbox.Create(ctx, message)
+	})
 }
 
-// handles CompleteOrder for business conditions
-func (wS *WorkOrderService) CompleteOrder(ctx context.Context, id uuid.UUID) error {
-	// check if workOrder exist by ID
-	workOrder, err := wS.wRepo.FindByID(ctx, id)
-	// handle error
+// handles CompleteOrder for business conditions, actor is kept in the status history. Returns
+// the order with its new version
+func (wS *WorkOrderService) CompleteOrder(ctx context.Context, id uuid.UUID, actor string, version *int) (*domain.WorkOrder, error) {
+	var completed *domain.WorkOrder
+
+	// every read and write below uses the same transaction, partial updates are not possible
+	err := wS.uow.Do(ctx, func(repos ports.TxRepositories) error {
+		// check if workOrder exist by ID
+		workOrder, err := repos.WorkOrders.FindByID(ctx, id)
+		// handle error
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(version, workOrder.Version); err != nil {
+			return err
+		}
+
+		switch workOrder.Status {
+		// handles workOrder.Status not been hable to change to Done while Done at current status
+		case domain.StatusDone:
+			return ErrWODone
+		// handles workOrder.Status not been hable to change to Done while Cancelled at current status
+		case domain.StatusCancelled:
+			return ErrWOCancelled
+		}
+
+		// check if customer exist by ID given by workOrder struct
+		customer, err := repos.Customers.FindByID(ctx, workOrder.CustomerID)
+		// handle error
+		if err != nil {
+			return err
+		}
+		// the customer may have changed since the order was created
+		if err := checkTransition(*customer, workOrder.Type); err != nil {
+			return err
+		}
+		// time for set StartDate or EndDate
+		timeNow := time.Now()
+
+		// set isActive to costumer
+		var customerEvent string
+		switch workOrder.Type {
+		case domain.TypeActivate:
+			customer.IsActive = true
+			customer.StartDate = &timeNow
+			customer.EndDate = nil
+			customerEvent = events.CustomerActivated
+
+		case domain.TypeCancell:
+			customer.IsActive = false
+			customer.EndDate = &timeNow
+			customerEvent = events.CustomerDeactivated
+		}
+
+		// make the change doing Update passing customer pointer
+		if err := repos.Customers.Update(ctx, *customer); err != nil {
+			return err
+		}
+		// StartDate and EndDate only keep the current period, the table keeps all of them
+		if err := recordPeriod(ctx, repos, *workOrder, customer.ID, timeNow); err != nil {
+			return err
+		}
+		customerMessage, err := outboxMessage(customerEvent, customer.ID, events.NewCustomerStatusPayload(*customer, workOrder.ID))
+		if err != nil {
+			return err
+		}
+		if err := repos.Outbox.Create(ctx, customerMessage); err != nil {
+			return err
+		}
+
+		// set Status to workOrder
+		previous := workOrder.Status
+		workOrder.Status = domain.StatusDone
+		// make the change to workOrder passing workOrder pointer
+		if err := repos.WorkOrders.Update(ctx, *workOrder); err != nil {
+			return err
+		}
+		// the repository moved the stored version
+		workOrder.Version++
+		completed = workOrder
+		// also when the customer was activated or deactivated, StartDate and EndDate only keep the last time
+		if err := recordStatus(ctx, repos, *workOrder, &previous, actor); err != nil {
+			return err
+		}
+
+		// event stored in the outbox, OutboxRelay sends it to the stream
+		message, err := outboxMessage(events.WorkOrderCompleted, workOrder.ID, events.NewWorkOrderPayload(*workOrder))
+		if err != nil {
+			return err
+		}
+		return repos.Outbox.Create(ctx, message)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return completed, nil
+}
 
-	switch workOrder.Status {
-	// handles workOrder.Status not been hable to change to Done while Done at current status
-	case domain.StatusDone:
-		return ErrWODone
-	// handles workOrder.Status not been hable to change to Done while Cancelled at current status
-	case domain.StatusCancelled:
-		return ErrWOCancelled
+// handles CancelOrder, only orders in status new can be cancelled, actor is kept in the status
+// history. Returns the order with its new version
+func (wS *WorkOrderService) CancelOrder(ctx context.Context, id uuid.UUID, reason, actor string, version *int) (*domain.WorkOrder, error) {
+	// reason is mandatory
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrNoReason
 	}
 
-	// check if customer exist by ID given by workOrder struct
-	customer, err := wS.cRepo.FindByID(ctx, workOrder.CustomerID)
-	// handle error
+	var cancelled *domain.WorkOrder
+
+	err := wS.uow.Do(ctx, func(repos ports.TxRepositories) error {
+		// check if workOrder exist by ID
+		workOrder, err := repos.WorkOrders.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(version, workOrder.Version); err != nil {
+			return err
+		}
+
+		switch workOrder.Status {
+		// done orders already changed the customer, can not be undone
+		case domain.StatusDone:
+			return ErrWODoneCancel
+		case domain.StatusCancelled:
+			return ErrWOCancelledCancel
+		}
+
+		// set Status and reason to workOrder, customer does not change
+		previous := workOrder.Status
+		workOrder.Status = domain.StatusCancelled
+		workOrder.CancellationReason = &reason
+		if err := repos.WorkOrders.Update(ctx, *workOrder); err != nil {
+			return err
+		}
+		// the repository moved the stored version
+		workOrder.Version++
+		cancelled = workOrder
+		if err := recordStatus(ctx, repos, *workOrder, &previous, actor); err != nil {
+			return err
+		}
+
+		// event stored in the outbox, OutboxRelay sends it to the stream
+		message, err := outboxMessage(events.WorkOrderCancelled, workOrder.ID, events.NewWorkOrderPayload(*workOrder))
+		if err != nil {
+			return err
+		}
+		return repos.Outbox.Create(ctx, message)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	// time for set StartDate or EndDate
-	timeNow := time.Now()
+	return cancelled, nil
+}
 
-	// set isActive to costumer
-	switch workOrder.Type {
-	case domain.TypeActivate:
-		customer.IsActive = true
-		customer.StartDate = &timeNow
-		customer.EndDate = nil
+func (wS *WorkOrderService) FindByID(ctx context.Context, id uuid.UUID) (*domain.WorkOrder, error) {
+	return wS.wRepo.FindByID(ctx, id)
+}
 
-	case domain.TypeCancell:
-		customer.IsActive = false
-		customer.EndDate = &timeNow
-	}
+func (wS *WorkOrderService) FindByFilter(ctx context.Context, filters ports.WorkOrderFilters) (ports.Page[domain.WorkOrder], error) {
+	return wS.wRepo.FindByFilter(ctx, filters)
+}
 
-	// make the change doing Update passing customer pointer
-	errUC := wS.cRepo.Update(ctx, *customer)
-	if errUC != nil {
-		return errUC
+func (wS *WorkOrderService) FindByCustomerID(ctx context.Context, customerID uuid.UUID, pagination ports.Pagination) (ports.Page[domain.WorkOrder], error) {
+	return wS.wRepo.FindByCustomerID(ctx, customerID, pagination)
+}
+
+// status history of the order, oldest first. Read in a unit of work so the order and its
+// history are the same snapshot
+func (wS *WorkOrderService) History(ctx context.Context, id uuid.UUID) ([]domain.WorkOrderStatusChange, error) {
+	var history []domain.WorkOrderStatusChange
+
+	err := wS.uow.Do(ctx, func(repos ports.TxRepositories) error {
+		// 404 for orders that do not exist instead of an empty history
+		if _, err := repos.WorkOrders.FindByID(ctx, id); err != nil {
+			return err
+		}
+		changes, err := repos.StatusChanges.FindByWorkOrderID(ctx, id)
+		if err != nil {
+			return err
+		}
+		history = changes
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return history, nil
+}
+
+// fields edited on the order, oldest first. Read in a unit of work like History
+func (wS *WorkOrderService) Changes(ctx context.Context, id uuid.UUID) ([]domain.WorkOrderChangeLog, error) {
+	var changeLogs []domain.WorkOrderChangeLog
 
-	// set Status to workOrder
-	workOrder.Status = domain.StatusDone
-	// make the change to workOrder passing workOrder pointer
-	errUWO := wS.wRepo.Update(ctx, *workOrder)
-	if errUWO != nil {
-		return errUWO
+	err := wS.uow.Do(ctx, func(repos ports.TxRepositories) error {
+		// 404 for orders that do not exist instead of an empty list
+		if _, err := repos.WorkOrders.FindByID(ctx, id); err != nil {
+			return err
+		}
+		found, err := repos.ChangeLogs.FindByWorkOrderID(ctx, id)
+		if err != nil {
+			return err
+		}
+		changeLogs = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return changeLogs, nil
+}
+
+// pairs of open orders of the same customer planned at the same time inside [since, until)
+func (wS *WorkOrderService) FindConflicts(ctx context.Context, since, until time.Time, customerID *uuid.UUID) ([]ports.WorkOrderConflict, error) {
+	return wS.wRepo.FindConflicts(ctx, since, until, customerID)
+}
+
+// handles Edit, description and planned dates can change only while the order is new, every
+// field changed is logged with the actor that did it
+func (wS *WorkOrderService) Edit(ctx context.Context, id uuid.UUID, changes ports.WorkOrderChanges, actor string, version *int) (*domain.WorkOrder, error) {
+	var edited *domain.WorkOrder
+
+	err := wS.uow.Do(ctx, func(repos ports.TxRepositories) error {
+		// check if workOrder exist by ID
+		workOrder, err := repos.WorkOrders.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(version, workOrder.Version); err != nil {
+			return err
+		}
+
+		switch workOrder.Status {
+		case domain.StatusDone:
+			return ErrWODoneEdit
+		case domain.StatusCancelled:
+			return ErrWOCancelledEdit
+		}
+
+		var changeLogs []domain.WorkOrderChangeLog
+		// keeps the log of a field only when its value really changes
+		logChange := func(field, oldValue, newValue string) {
+			if oldValue == newValue {
+				return
+			}
+			changeLogs = append(changeLogs, domain.WorkOrderChangeLog{
+				ID:          uuid.New(),
+				WorkOrderID: workOrder.ID,
+				Actor:       actor,
+				Field:       field,
+				OldValue:    oldValue,
+				NewValue:    newValue,
+			})
+		}
 
-	// map workOrder into json to send it to redis
-	workOrderJSON, err := json.Marshal(workOrder)
+		if changes.Description != nil {
+			description := strings.TrimSpace(*changes.Description)
+			if description == "" {
+				return ErrWOEmpty
+			}
+			logChange("description", workOrder.Description, description)
+			workOrder.Description = description
+		}
+		// dates before the change, for the rescheduled event
+		previousBegin, previousEnd := workOrder.PlannedDateBegin, workOrder.PlannedDateEnd
+
+		if changes.PlannedDateBegin != nil {
+			// same as Create, only checked when the begin moves so old orders can still be edited
+			if !changes.PlannedDateBegin.Equal(workOrder.PlannedDateBegin) {
+				if err := wS.policy.checkLeadTime(*changes.PlannedDateBegin, time.Now()); err != nil {
+					return err
+				}
+			}
+			logChange("planned_date_begin", workOrder.PlannedDateBegin.Format(time.RFC3339), changes.PlannedDateBegin.Format(time.RFC3339))
+			workOrder.PlannedDateBegin = *changes.PlannedDateBegin
+		}
+		if changes.PlannedDateEnd != nil {
+			logChange("planned_date_end", workOrder.PlannedDateEnd.Format(time.RFC3339), changes.PlannedDateEnd.Format(time.RFC3339))
+			workOrder.PlannedDateEnd = *changes.PlannedDateEnd
+		}
+
+		// same rules as Create with the resulting dates #business logic 2, only when they change so
+		// a stricter policy does not block editing the description of old orders
+		rescheduled := !workOrder.PlannedDateBegin.Equal(previousBegin) || !workOrder.PlannedDateEnd.Equal(previousEnd)
+		if rescheduled {
+			if err := wS.policy.checkWindow(workOrder.PlannedDateBegin, workOrder.PlannedDateEnd); err != nil {
+				return err
+			}
+			if err := checkOverlap(ctx, repos.WorkOrders, *workOrder); err != nil {
+				return err
+			}
+		}
+
+		edited = workOrder
+		// nothing changed, nothing to save
+		if len(changeLogs) == 0 {
+			return nil
+		}
+
+		if err := repos.WorkOrders.Update(ctx, *workOrder); err != nil {
+			return err
+		}
+		// the repository moved the stored version
+		workOrder.Version++
+		for _, changeLog := range changeLogs {
+			if err := repos.ChangeLogs.Create(ctx, changeLog); err != nil {
+				return err
+			}
+		}
+
+		// only the dates matter to consumers, a new description sends nothing
+		if !rescheduled {
+			return nil
+		}
+		message, err := outboxMessage(events.WorkOrderRescheduled, workOrder.ID, events.NewWorkOrderRescheduledPayload(*workOrder, previousBegin, previousEnd))
+		if err != nil {
+			return err
+		}
+		return repos.Outbox.Create(ctx, message)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	// send the event to redis stream
-	err = wS.redis.XAdd(ctx, &redis.XAddArgs{
-		Stream: wS.streamName,
-		Values: map[string]interface{}{
-			"work_order_completed": string(workOrderJSON),
-		},
-	}).Err()
+	return edited, nil
+}
 
+// the window of an open order can not overlap another open order of the same customer, the
+// exclusion constraint catches two requests doing it at the same time
+func checkOverlap(ctx context.Context, wRepo ports.WorkOrderRepository, workOrder domain.WorkOrder) error {
+	overlapping, err := wRepo.FindOverlapping(ctx, workOrder.CustomerID, workOrder.PlannedDateBegin, workOrder.PlannedDateEnd, workOrder.ID)
 	if err != nil {
 		return err
 	}
-
+	if len(overlapping) > 0 {
+		return ErrWOOverlap
+	}
 	return nil
 }
 
-func (wS *WorkOrderService) FindByID(ctx context.Context, id uuid.UUID) (*domain.WorkOrder, error) {
-	return wS.wRepo.FindByID(ctx, id)
+// saves the move of workOrder from previous to its current status, previous is nil on creation
+func recordStatus(ctx context.Context, repos ports.TxRepositories, workOrder domain.WorkOrder, previous *domain.Status, actor string) error {
+	return repos.StatusChanges.Create(ctx, domain.WorkOrderStatusChange{
+		ID:          uuid.New(),
+		WorkOrderID: workOrder.ID,
+		Actor:       actor,
+		OldStatus:   previous,
+		NewStatus:   workOrder.Status,
+		CreatedAt:   time.Now(),
+	})
 }
 
-func (wS *WorkOrderService) FindByFilter(ctx context.Context, filters ports.WorkOrderFilters) ([]domain.WorkOrder, error) {
-	return wS.wRepo.FindByFilter(ctx, filters)
+// opens a service period when an activation is completed and closes it with a cancellation
+func recordPeriod(ctx context.Context, repos ports.TxRepositories, workOrder domain.WorkOrder, customerID uuid.UUID, at time.Time) error {
+	switch workOrder.Type {
+	case domain.TypeActivate:
+		return repos.Periods.Open(ctx, domain.CustomerServicePeriod{
+			ID:               uuid.New(),
+			CustomerID:       customerID,
+			StartedAt:        at,
+			StartWorkOrderID: &workOrder.ID,
+		})
+	case domain.TypeCancell:
+		return repos.Periods.Close(ctx, customerID, at, workOrder.ID)
+	}
+	return nil
+}
+
+// an activate order needs an inactive customer and a cancel order an active one
+func checkTransition(customer domain.Customer, woType domain.Type) error {
+	switch customer.IsActive {
+	case true:
+		// trying to activate a customer already active
+		if woType == domain.TypeActivate {
+			return ErrAA
+		}
+	case false:
+		// trying to cancel a customer notActive
+		if woType == domain.TypeCancell {
+			return ErrCC
+		}
+	}
+	return nil
 }
 
-func (wS *WorkOrderService) FindByCustomerID(ctx context.Context, customerID uuid.UUID) ([]domain.WorkOrder, error) {
-	return wS.wRepo.FindByCustomerID(ctx, customerID)
+// expected is the version the client sent in If-Match, nil when it did not send one
+func checkVersion(expected *int, current int) error {
+	if expected != nil && *expected != current {
+		return ErrVersionMismatch
+	}
+	return nil
 }
 
-func (wS *WorkOrderService) Update(ctx context.Context, workOrder domain.WorkOrder) error {
-	return wS.wRepo.Update(ctx, workOrder)
+// wraps the payload in the envelope of eventType, the events tests check every payload against
+// its schema
+func outboxMessage(eventType string, aggregateID uuid.UUID, payload any) (domain.OutboxMessage, error) {
+	envelope, err := events.New(eventType, aggregateID, payload)
+	if err != nil {
+		return domain.OutboxMessage{}, err
+	}
+	data, err := envelope.Encode()
+	if err != nil {
+		return domain.OutboxMessage{}, err
+	}
+	return domain.OutboxMessage{
+		ID:      envelope.ID,
+		Event:   envelope.Type,
+		Payload: string(data),
+	}, nil
 }
@@ -0,0 +1,650 @@
+// internal/core/services/workorder_service_test.go
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/krud3/prueba-tecnica/internal/adapters/memory"
+	"github.com/krud3/prueba-tecnica/internal/core/domain"
+	"github.com/krud3/prueba-tecnica/internal/core/events"
+	"github.com/krud3/prueba-tecnica/internal/core/ports"
+	"github.com/krud3/prueba-tecnica/internal/core/services"
+)
+
+// the services on the memory adapters, the outbox is sent to a RecordingPublisher
+type testEnv struct {
+	customers       ports.CustomerRepository
+	workOrders      ports.WorkOrderRepository
+	periods         ports.CustomerServicePeriodRepository
+	service         *services.WorkOrderService
+	customerService *services.CustomerService
+	relay           *services.OutboxRelay
+	publisher       *memory.RecordingPublisher
+	// events already returned by published
+	seen int
+}
+
+func newTestEnv() *testEnv {
+	db := memory.NewDB()
+	customers := memory.NewMemoryCustomerRepository(db)
+	workOrders := memory.NewMemoryWorkOrderRepository(db)
+	uow := memory.NewMemoryUnitOfWork(db)
+	publisher := memory.NewRecordingPublisher()
+	return &testEnv{
+		customers:       customers,
+		workOrders:      workOrders,
+		periods:         memory.NewMemoryCustomerServicePeriodRepository(db),
+		service:         services.NewWorkOrderService(workOrders, customers, uow, services.DefaultPolicy()),
+		customerService: services.NewCustomerService(customers, uow),
+		relay:           services.NewOutboxRelay(memory.NewMemoryUnitOfWork(db), publisher, time.Second, 10),
+		publisher:       publisher,
+	}
+}
+
+// sends the outbox and returns the events published since the last call
+func (e *testEnv) published(t *testing.T) []string {
+	t.Helper()
+	if _, err := e.relay.Drain(context.Background()); err != nil {
+		t.Fatalf("draining the outbox: %v", err)
+	}
+	var names []string
+	all := e.publisher.Events()
+	for _, event := range all[e.seen:] {
+		names = append(names, event.Event)
+	}
+	e.seen = len(all)
+	return names
+}
+
+func (e *testEnv) createCustomer(t *testing.T, active bool) domain.Customer {
+	t.Helper()
+	customer := domain.Customer{ID: uuid.New(), FirstName: "Ana", LastName: "Pérez", Address: "calle 1", IsActive: active, Version: 1}
+	if active {
+		start := time.Now().Add(-24 * time.Hour)
+		customer.StartDate = &start
+	}
+	if err := e.customers.Create(context.Background(), customer); err != nil {
+		t.Fatalf("creating customer: %v", err)
+	}
+	return customer
+}
+
+// creates an order with the service, its events are left out of the next published
+func (e *testEnv) createOrder(t *testing.T, customerID uuid.UUID, woType domain.Type, begin time.Time) domain.WorkOrder {
+	t.Helper()
+	workOrder := newOrder(customerID, woType, begin)
+	if err := e.service.Create(context.Background(), &workOrder, "tester"); err != nil {
+		t.Fatalf("creating work order: %v", err)
+	}
+	e.published(t)
+	return workOrder
+}
+
+func (e *testEnv) findCustomer(t *testing.T, id uuid.UUID) *domain.Customer {
+	t.Helper()
+	customer, err := e.customers.FindByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("finding customer: %v", err)
+	}
+	return customer
+}
+
+// one hour window starting at begin
+func newOrder(customerID uuid.UUID, woType domain.Type, begin time.Time) domain.WorkOrder {
+	return domain.WorkOrder{
+		CustomerID:       customerID,
+		Description:      "visita técnica",
+		PlannedDateBegin: begin,
+		PlannedDateEnd:   begin.Add(time.Hour),
+		Type:             woType,
+	}
+}
+
+// on the hour two days from now, the default policy only forbids the past
+func plannedBase() time.Time {
+	return time.Now().Truncate(time.Hour).Add(48 * time.Hour)
+}
+
+// errors.Is for the sentinels, rule errors are built with their limit so only the code is compared
+func sameError(err, want error) bool {
+	if errors.Is(err, want) {
+		return true
+	}
+	var got, expected *domain.Error
+	return errors.As(err, &got) && errors.As(want, &expected) && expected.Rule != "" && got.Code == expected.Code
+}
+
+func TestWorkOrderServiceCreate(t *testing.T) {
+	begin := plannedBase()
+	tooLong := domain.NewRuleError("planned_window_too_long", "", services.RuleMaxDuration, "2h")
+
+	tests := []struct {
+		name string
+		// customer active before the order
+		active bool
+		// open order the customer already has at the same time, saved without the service
+		existing domain.Type
+		edit     func(workOrder *domain.WorkOrder)
+		wantErr  error
+	}{
+		{name: "activate an inactive customer"},
+		{name: "cancel an active customer", active: true, edit: func(w *domain.WorkOrder) { w.Type = domain.TypeCancell }},
+		{name: "invalid type", edit: func(w *domain.WorkOrder) { w.Type = "otro" }, wantErr: services.ErrWOType},
+		{name: "empty description", edit: func(w *domain.WorkOrder) { w.Description = "  " }, wantErr: services.ErrWOEmpty},
+		{name: "end before begin", edit: func(w *domain.WorkOrder) { w.PlannedDateEnd = w.PlannedDateBegin.Add(-time.Hour) }, wantErr: services.ErrDateOrder},
+		{name: "window longer than the max duration", edit: func(w *domain.WorkOrder) { w.PlannedDateEnd = w.PlannedDateBegin.Add(3 * time.Hour) }, wantErr: tooLong},
+		{name: "begin in the past", edit: func(w *domain.WorkOrder) {
+			w.PlannedDateBegin = time.Now().Add(-time.Hour)
+			w.PlannedDateEnd = time.Now()
+		}, wantErr: services.ErrDatePast},
+		{name: "activate an active customer", active: true, wantErr: services.ErrAA},
+		{name: "cancel an inactive customer", edit: func(w *domain.WorkOrder) { w.Type = domain.TypeCancell }, wantErr: services.ErrCC},
+		{name: "customer that does not exist", edit: func(w *domain.WorkOrder) { w.CustomerID = uuid.New() }, wantErr: services.ErrCNotFound},
+		{name: "second open order of the same type", existing: domain.TypeActivate, edit: func(w *domain.WorkOrder) {
+			w.PlannedDateBegin = w.PlannedDateBegin.Add(24 * time.Hour)
+			w.PlannedDateEnd = w.PlannedDateEnd.Add(24 * time.Hour)
+		}, wantErr: services.ErrWOOpen},
+		{name: "overlaps another open order", existing: domain.TypeCancell, wantErr: services.ErrWOOverlap},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			env := newTestEnv()
+			customer := env.createCustomer(t, tc.active)
+			if tc.existing != "" {
+				existing := newOrder(customer.ID, tc.existing, begin)
+				existing.ID = uuid.New()
+				existing.Status = domain.StatusNew
+				existing.Version = 1
+				if err := env.workOrders.Create(context.Background(), existing); err != nil {
+					t.Fatalf("creating the existing order: %v", err)
+				}
+			}
+
+			workOrder := newOrder(customer.ID, domain.TypeActivate, begin)
+			if tc.edit != nil {
+				tc.edit(&workOrder)
+			}
+			err := env.service.Create(context.Background(), &workOrder, "tester")
+			if !sameError(err, tc.wantErr) {
+				t.Fatalf("got %v, want %v", err, tc.wantErr)
+			}
+
+			published := env.published(t)
+			if tc.wantErr != nil {
+				if len(published) > 0 {
+					t.Fatalf("a rejected order published %v", published)
+				}
+				return
+			}
+			if want := []string{events.WorkOrderCreated}; !slices.Equal(published, want) {
+				t.Fatalf("published %v, want %v", published, want)
+			}
+			if workOrder.ID == uuid.Nil || workOrder.Status != domain.StatusNew || workOrder.Version != 1 {
+				t.Fatalf("created order: id %s status %s version %d", workOrder.ID, workOrder.Status, workOrder.Version)
+			}
+			// the customer changes only when the order is completed
+			if stored := env.findCustomer(t, customer.ID); stored.IsActive != tc.active {
+				t.Fatalf("customer active %t after creating the order, want %t", stored.IsActive, tc.active)
+			}
+		})
+	}
+}
+
+func TestWorkOrderServiceCompleteOrder(t *testing.T) {
+	stale := 7
+
+	tests := []struct {
+		name   string
+		active bool
+		woType domain.Type
+		// runs on the order before completing it
+		prepare func(t *testing.T, env *testEnv, workOrder domain.WorkOrder)
+		version *int
+		wantErr error
+		// customer after completing, only without error
+		wantActive    bool
+		wantPublished []string
+	}{
+		{
+			name:          "activation activates the customer",
+			woType:        domain.TypeActivate,
+			wantActive:    true,
+			wantPublished: []string{events.CustomerActivated, events.WorkOrderCompleted},
+		},
+		{
+			name:          "cancellation deactivates the customer",
+			active:        true,
+			woType:        domain.TypeCancell,
+			wantActive:    false,
+			wantPublished: []string{events.CustomerDeactivated, events.WorkOrderCompleted},
+		},
+		{
+			name:   "already done",
+			woType: domain.TypeActivate,
+			prepare: func(t *testing.T, env *testEnv, workOrder domain.WorkOrder) {
+				if _, err := env.service.CompleteOrder(context.Background(), workOrder.ID, "tester", nil); err != nil {
+					t.Fatal(err)
+				}
+				env.published(t)
+			},
+			wantErr: services.ErrWODone,
+		},
+		{
+			name:   "cancelled",
+			woType: domain.TypeActivate,
+			prepare: func(t *testing.T, env *testEnv, workOrder domain.WorkOrder) {
+				if _, err := env.service.CancelOrder(context.Background(), workOrder.ID, "sin acceso", "tester", nil); err != nil {
+					t.Fatal(err)
+				}
+				env.published(t)
+			},
+			wantErr: services.ErrWOCancelled,
+		},
+		{
+			name:   "customer activated since the order was created",
+			woType: domain.TypeActivate,
+			prepare: func(t *testing.T, env *testEnv, workOrder domain.WorkOrder) {
+				customer := *env.findCustomer(t, workOrder.CustomerID)
+				customer.IsActive = true
+				if err := env.customers.Update(context.Background(), customer); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: services.ErrAA,
+		},
+		{
+			name:    "stale If-Match",
+			woType:  domain.TypeActivate,
+			version: &stale,
+			wantErr: services.ErrVersionMismatch,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			env := newTestEnv()
+			customer := env.createCustomer(t, tc.active)
+			workOrder := env.createOrder(t, customer.ID, tc.woType, plannedBase())
+			if tc.prepare != nil {
+				tc.prepare(t, env, workOrder)
+			}
+			before := env.findCustomer(t, customer.ID)
+
+			completed, err := env.service.CompleteOrder(context.Background(), workOrder.ID, "tester", tc.version)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got %v, want %v", err, tc.wantErr)
+			}
+
+			published := env.published(t)
+			after := env.findCustomer(t, customer.ID)
+			if tc.wantErr != nil {
+				if len(published) > 0 {
+					t.Fatalf("a rejected completion published %v", published)
+				}
+				if after.IsActive != before.IsActive || after.Version != before.Version {
+					t.Fatalf("a rejected completion changed the customer")
+				}
+				return
+			}
+
+			if !slices.Equal(published, tc.wantPublished) {
+				t.Fatalf("published %v, want %v", published, tc.wantPublished)
+			}
+			if completed.Status != domain.StatusDone || completed.Version != workOrder.Version+1 {
+				t.Fatalf("completed order: status %s version %d, want %s %d", completed.Status, completed.Version, domain.StatusDone, workOrder.Version+1)
+			}
+			if after.IsActive != tc.wantActive {
+				t.Fatalf("customer active %t, want %t", after.IsActive, tc.wantActive)
+			}
+			// the dates of the current period
+			if tc.wantActive && (after.StartDate == nil || after.EndDate != nil) {
+				t.Fatalf("activated customer: start %v end %v, want start and no end", after.StartDate, after.EndDate)
+			}
+			if !tc.wantActive && after.EndDate == nil {
+				t.Fatal("deactivated customer without end date")
+			}
+		})
+	}
+}
+
+func TestWorkOrderServiceCancelOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		reason  string
+		prepare func(t *testing.T, env *testEnv, workOrder domain.WorkOrder)
+		wantErr error
+	}{
+		{name: "new order", reason: "  el cliente no estaba  "},
+		{name: "without reason", reason: " ", wantErr: services.ErrNoReason},
+		{
+			name:   "done",
+			reason: "tarde",
+			prepare: func(t *testing.T, env *testEnv, workOrder domain.WorkOrder) {
+				if _, err := env.service.CompleteOrder(context.Background(), workOrder.ID, "tester", nil); err != nil {
+					t.Fatal(err)
+				}
+				env.published(t)
+			},
+			wantErr: services.ErrWODoneCancel,
+		},
+		{
+			name:   "already cancelled",
+			reason: "otra vez",
+			prepare: func(t *testing.T, env *testEnv, workOrder domain.WorkOrder) {
+				if _, err := env.service.CancelOrder(context.Background(), workOrder.ID, "primera", "tester", nil); err != nil {
+					t.Fatal(err)
+				}
+				env.published(t)
+			},
+			wantErr: services.ErrWOCancelledCancel,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			env := newTestEnv()
+			customer := env.createCustomer(t, false)
+			workOrder := env.createOrder(t, customer.ID, domain.TypeActivate, plannedBase())
+			if tc.prepare != nil {
+				tc.prepare(t, env, workOrder)
+			}
+			before := env.findCustomer(t, customer.ID)
+
+			cancelled, err := env.service.CancelOrder(context.Background(), workOrder.ID, tc.reason, "tester", nil)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got %v, want %v", err, tc.wantErr)
+			}
+
+			published := env.published(t)
+			// cancelling never touches the customer
+			if after := env.findCustomer(t, customer.ID); after.IsActive != before.IsActive || after.Version != before.Version {
+				t.Fatal("cancelling the order changed the customer")
+			}
+			if tc.wantErr != nil {
+				if len(published) > 0 {
+					t.Fatalf("a rejected cancellation published %v", published)
+				}
+				return
+			}
+
+			if want := []string{events.WorkOrderCancelled}; !slices.Equal(published, want) {
+				t.Fatalf("published %v, want %v", published, want)
+			}
+			if cancelled.Status != domain.StatusCancelled || cancelled.CancellationReason == nil || *cancelled.CancellationReason != "el cliente no estaba" {
+				t.Fatalf("cancelled order: status %s reason %v", cancelled.Status, cancelled.CancellationReason)
+			}
+		})
+	}
+}
+
+func TestWorkOrderServiceEdit(t *testing.T) {
+	begin := plannedBase()
+	stale := 7
+	tooLong := domain.NewRuleError("planned_window_too_long", "", services.RuleMaxDuration, "2h")
+	description := func(value string) func(domain.WorkOrder) ports.WorkOrderChanges {
+		return func(domain.WorkOrder) ports.WorkOrderChanges { return ports.WorkOrderChanges{Description: &value} }
+	}
+	// moves the begin and the end of the order
+	dates := func(beginBy, endBy time.Duration) func(domain.WorkOrder) ports.WorkOrderChanges {
+		return func(w domain.WorkOrder) ports.WorkOrderChanges {
+			newBegin, newEnd := w.PlannedDateBegin.Add(beginBy), w.PlannedDateEnd.Add(endBy)
+			return ports.WorkOrderChanges{PlannedDateBegin: &newBegin, PlannedDateEnd: &newEnd}
+		}
+	}
+
+	tests := []struct {
+		name string
+		// order saved without the service yesterday for three hours, it breaks every rule of the policy
+		legacy bool
+		// another open order of the customer the next day, saved without the service
+		next    bool
+		prepare func(t *testing.T, env *testEnv, workOrder domain.WorkOrder)
+		changes func(workOrder domain.WorkOrder) ports.WorkOrderChanges
+		version *int
+		wantErr error
+		// fields of the change log rows, in order
+		wantFields    []string
+		wantPublished []string
+	}{
+		{
+			name:       "description only skips the policy",
+			legacy:     true,
+			changes:    description("  cambio de equipo  "),
+			wantFields: []string{"description"},
+		},
+		{
+			name:    "same description",
+			changes: description("visita técnica"),
+		},
+		{
+			name:          "reschedule a day later",
+			changes:       dates(24*time.Hour, 24*time.Hour),
+			wantFields:    []string{"planned_date_begin", "planned_date_end"},
+			wantPublished: []string{events.WorkOrderRescheduled},
+		},
+		{
+			name:          "only the end moves",
+			changes:       dates(0, 30*time.Minute),
+			wantFields:    []string{"planned_date_end"},
+			wantPublished: []string{events.WorkOrderRescheduled},
+		},
+		{
+			name: "description and dates",
+			changes: func(w domain.WorkOrder) ports.WorkOrderChanges {
+				changes := dates(time.Hour, time.Hour)(w)
+				changes.Description = description("cambio de equipo")(w).Description
+				return changes
+			},
+			wantFields:    []string{"description", "planned_date_begin", "planned_date_end"},
+			wantPublished: []string{events.WorkOrderRescheduled},
+		},
+		{name: "end before the begin", changes: dates(0, -2*time.Hour), wantErr: services.ErrDateOrder},
+		{name: "window longer than the max duration", changes: dates(0, 2*time.Hour), wantErr: tooLong},
+		{name: "begin moved to the past", changes: dates(-72*time.Hour, -72*time.Hour), wantErr: services.ErrDatePast},
+		{
+			name:          "old order shortened",
+			legacy:        true,
+			changes:       dates(0, -time.Hour),
+			wantFields:    []string{"planned_date_end"},
+			wantPublished: []string{events.WorkOrderRescheduled},
+		},
+		// the begin stays in the past, only the window is checked again
+		{name: "end of an old order moved", legacy: true, changes: dates(0, -30*time.Minute), wantErr: tooLong},
+		{name: "overlaps another open order", next: true, changes: dates(24*time.Hour, 24*time.Hour), wantErr: services.ErrWOOverlap},
+		{name: "blank description", changes: description(" "), wantErr: services.ErrWOEmpty},
+		{name: "stale If-Match", changes: description("cambio de equipo"), version: &stale, wantErr: services.ErrVersionMismatch},
+		{
+			name: "done",
+			prepare: func(t *testing.T, env *testEnv, workOrder domain.WorkOrder) {
+				if _, err := env.service.CompleteOrder(context.Background(), workOrder.ID, "tester", nil); err != nil {
+					t.Fatal(err)
+				}
+				env.published(t)
+			},
+			changes: description("cambio de equipo"),
+			wantErr: services.ErrWODoneEdit,
+		},
+		{
+			name: "cancelled",
+			prepare: func(t *testing.T, env *testEnv, workOrder domain.WorkOrder) {
+				if _, err := env.service.CancelOrder(context.Background(), workOrder.ID, "sin acceso", "tester", nil); err != nil {
+					t.Fatal(err)
+				}
+				env.published(t)
+			},
+			changes: description("cambio de equipo"),
+			wantErr: services.ErrWOCancelledEdit,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTestEnv()
+			customer := env.createCustomer(t, false)
+
+			var workOrder domain.WorkOrder
+			if tc.legacy {
+				workOrder = newOrder(customer.ID, domain.TypeActivate, time.Now().Truncate(time.Hour).Add(-24*time.Hour))
+				workOrder.ID = uuid.New()
+				workOrder.PlannedDateEnd = workOrder.PlannedDateBegin.Add(3 * time.Hour)
+				workOrder.Status = domain.StatusNew
+				workOrder.Version = 1
+				if err := env.workOrders.Create(ctx, workOrder); err != nil {
+					t.Fatalf("creating the old order: %v", err)
+				}
+			} else {
+				workOrder = env.createOrder(t, customer.ID, domain.TypeActivate, begin)
+			}
+			if tc.next {
+				next := newOrder(customer.ID, domain.TypeCancell, begin.Add(24*time.Hour))
+				next.ID = uuid.New()
+				next.Status = domain.StatusNew
+				next.Version = 1
+				if err := env.workOrders.Create(ctx, next); err != nil {
+					t.Fatalf("creating the next order: %v", err)
+				}
+			}
+			if tc.prepare != nil {
+				tc.prepare(t, env, workOrder)
+			}
+			before, err := env.workOrders.FindByID(ctx, workOrder.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			changes := tc.changes(*before)
+
+			edited, err := env.service.Edit(ctx, workOrder.ID, changes, "editor", tc.version)
+			if !sameError(err, tc.wantErr) {
+				t.Fatalf("got %v, want %v", err, tc.wantErr)
+			}
+
+			published := env.published(t)
+			changeLogs, err := env.service.Changes(ctx, workOrder.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			after, err := env.workOrders.FindByID(ctx, workOrder.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.wantErr != nil {
+				if len(published) > 0 || len(changeLogs) > 0 || after.Version != before.Version {
+					t.Fatalf("a rejected edit published %v, logged %d changes and moved the version to %d", published, len(changeLogs), after.Version)
+				}
+				return
+			}
+
+			if !slices.Equal(published, tc.wantPublished) {
+				t.Fatalf("published %v, want %v", published, tc.wantPublished)
+			}
+			var fields []string
+			for _, changeLog := range changeLogs {
+				if changeLog.Actor != "editor" {
+					t.Fatalf("change of %s by %q, want editor", changeLog.Field, changeLog.Actor)
+				}
+				fields = append(fields, changeLog.Field)
+			}
+			if !slices.Equal(fields, tc.wantFields) {
+				t.Fatalf("logged %v, want %v", fields, tc.wantFields)
+			}
+			// the version moves only when something was saved
+			wantVersion := before.Version
+			if len(tc.wantFields) > 0 {
+				wantVersion++
+			}
+			if edited.Version != wantVersion || after.Version != wantVersion {
+				t.Fatalf("version %d returned and %d stored, want %d", edited.Version, after.Version, wantVersion)
+			}
+			if changes.Description != nil && after.Description != strings.TrimSpace(*changes.Description) {
+				t.Fatalf("description %q", after.Description)
+			}
+			if changes.PlannedDateBegin != nil && (!after.PlannedDateBegin.Equal(*changes.PlannedDateBegin) || !after.PlannedDateEnd.Equal(*changes.PlannedDateEnd)) {
+				t.Fatalf("planned %s - %s, want %s - %s", after.PlannedDateBegin, after.PlannedDateEnd, changes.PlannedDateBegin, changes.PlannedDateEnd)
+			}
+		})
+	}
+}
+
+func TestWorkOrderServiceHistory(t *testing.T) {
+	tests := []struct {
+		name string
+		// moves the order after creating it, by closer
+		move func(env *testEnv, id uuid.UUID) error
+		// each step as old->new by actor, oldest first
+		want []string
+	}{
+		{
+			name: "created",
+			want: []string{"->new by tester"},
+		},
+		{
+			name: "completed",
+			move: func(env *testEnv, id uuid.UUID) error {
+				_, err := env.service.CompleteOrder(context.Background(), id, "closer", nil)
+				return err
+			},
+			want: []string{"->new by tester", "new->done by closer"},
+		},
+		{
+			name: "cancelled",
+			move: func(env *testEnv, id uuid.UUID) error {
+				_, err := env.service.CancelOrder(context.Background(), id, "sin acceso", "closer", nil)
+				return err
+			},
+			want: []string{"->new by tester", "new->cancelled by closer"},
+		},
+		{
+			name: "edited",
+			move: func(env *testEnv, id uuid.UUID) error {
+				description := "cambio de equipo"
+				_, err := env.service.Edit(context.Background(), id, ports.WorkOrderChanges{Description: &description}, "closer", nil)
+				return err
+			},
+			// the edits go to the change log, not to the status history
+			want: []string{"->new by tester"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			env := newTestEnv()
+			customer := env.createCustomer(t, false)
+			workOrder := env.createOrder(t, customer.ID, domain.TypeActivate, plannedBase())
+			if tc.move != nil {
+				if err := tc.move(env, workOrder.ID); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			history, err := env.service.History(context.Background(), workOrder.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, change := range history {
+				old := ""
+				if change.OldStatus != nil {
+					old = string(*change.OldStatus)
+				}
+				got = append(got, old+"->"+string(change.NewStatus)+" by "+change.Actor)
+				if change.WorkOrderID != workOrder.ID || change.CreatedAt.IsZero() {
+					t.Fatalf("change %+v of another order or without date", change)
+				}
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Fatalf("history %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("unknown order", func(t *testing.T) {
+		env := newTestEnv()
+		if _, err := env.service.History(context.Background(), uuid.New()); !errors.Is(err, services.ErrWONotFound) {
+			t.Fatalf("got %v, want %v", err, services.ErrWONotFound)
+		}
+	})
+}
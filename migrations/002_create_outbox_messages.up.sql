@@ -0,0 +1,15 @@
+-- migrations/002_create_outbox_messages.up.sql
+
+-- Outbox table, events are written in the same transaction as the change
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id UUID PRIMARY KEY,
+    event VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    published_at TIMESTAMPTZ
+);
+
+-- The relay only reads pending messages
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_pending ON outbox_messages (created_at) WHERE published_at IS NULL;
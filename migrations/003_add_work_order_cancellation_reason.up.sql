@@ -0,0 +1,4 @@
+-- migrations/003_add_work_order_cancellation_reason.up.sql
+
+-- Reason given when a work order is cancelled, null for the rest
+ALTER TABLE work_orders ADD COLUMN IF NOT EXISTS cancellation_reason TEXT;
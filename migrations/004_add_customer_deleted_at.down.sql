@@ -0,0 +1,4 @@
+-- migrations/004_add_customer_deleted_at.down.sql
+
+DROP INDEX IF EXISTS idx_customers_deleted_at;
+ALTER TABLE customers DROP COLUMN IF EXISTS deleted_at;
\ No newline at end of file
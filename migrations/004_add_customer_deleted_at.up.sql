@@ -0,0 +1,6 @@
+-- migrations/004_add_customer_deleted_at.up.sql
+
+-- Soft delete for customers, deleted ones are hidden by gorm
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_customers_deleted_at ON customers (deleted_at);
@@ -0,0 +1,14 @@
+-- migrations/005_create_work_order_change_logs.up.sql
+
+-- One row per field changed when a work order is edited
+CREATE TABLE IF NOT EXISTS work_order_change_logs (
+    id UUID PRIMARY KEY,
+    work_order_id UUID NOT NULL REFERENCES work_orders(id),
+    actor VARCHAR(255) NOT NULL,
+    field VARCHAR(50) NOT NULL,
+    old_value TEXT NOT NULL,
+    new_value TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_work_order_change_logs_work_order_id ON work_order_change_logs (work_order_id, created_at);
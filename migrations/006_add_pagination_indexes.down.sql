@@ -0,0 +1,5 @@
+-- migrations/006_add_pagination_indexes.down.sql
+
+DROP INDEX IF EXISTS idx_work_orders_customer_id_created_at_id;
+DROP INDEX IF EXISTS idx_work_orders_created_at_id;
+DROP INDEX IF EXISTS idx_customers_created_at_id;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- migrations/006_add_pagination_indexes.up.sql
+
+-- Keyset pagination orders and seeks by (created_at, id)
+CREATE INDEX IF NOT EXISTS idx_customers_created_at_id ON customers (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_work_orders_created_at_id ON work_orders (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_work_orders_customer_id_created_at_id ON work_orders (customer_id, created_at, id);
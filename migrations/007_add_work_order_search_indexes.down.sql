@@ -0,0 +1,5 @@
+-- migrations/007_add_work_order_search_indexes.down.sql
+
+DROP INDEX IF EXISTS idx_work_orders_type_status;
+DROP INDEX IF EXISTS idx_work_orders_description_trgm;
+-- pg_trgm is left installed, other objects may use it
\ No newline at end of file
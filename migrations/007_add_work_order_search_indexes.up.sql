@@ -0,0 +1,9 @@
+-- migrations/007_add_work_order_search_indexes.up.sql
+
+-- Trigram index so description ILIKE '%text%' does not scan the whole table
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_work_orders_description_trgm ON work_orders USING GIN (description gin_trgm_ops);
+
+-- Filters by type and status
+CREATE INDEX IF NOT EXISTS idx_work_orders_type_status ON work_orders (type, status);
@@ -0,0 +1,4 @@
+-- migrations/009_add_version_columns.down.sql
+
+ALTER TABLE work_orders DROP COLUMN IF EXISTS version;
+ALTER TABLE customers DROP COLUMN IF EXISTS version;
\ No newline at end of file
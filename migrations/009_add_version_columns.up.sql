@@ -0,0 +1,5 @@
+-- migrations/009_add_version_columns.up.sql
+
+-- Optimistic concurrency, updates only apply when the version did not change since the row was read
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE work_orders ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+-- migrations/010_add_open_work_order_unique_index.down.sql
+
+DROP INDEX IF EXISTS uq_work_orders_open_customer_type;
\ No newline at end of file
@@ -0,0 +1,19 @@
+-- migrations/010_add_open_work_order_unique_index.up.sql
+
+-- Orders created before this rule can repeat, the oldest open one of each type is kept and the rest are cancelled
+UPDATE work_orders
+SET status = 'cancelled',
+    cancellation_reason = 'orden duplicada, cancelada al limitar a una orden abierta por tipo',
+    version = version + 1
+WHERE status = 'new'
+  AND id IN (
+    SELECT id FROM (
+      SELECT id, ROW_NUMBER() OVER (PARTITION BY customer_id, type ORDER BY created_at, id) AS position
+      FROM work_orders
+      WHERE status = 'new'
+    ) AS open_orders
+    WHERE position > 1
+  );
+
+-- At most one open (status new) order of each type per customer
+CREATE UNIQUE INDEX IF NOT EXISTS uq_work_orders_open_customer_type ON work_orders (customer_id, type) WHERE status = 'new';
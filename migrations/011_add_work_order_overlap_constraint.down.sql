@@ -0,0 +1,4 @@
+-- migrations/011_add_work_order_overlap_constraint.down.sql
+
+ALTER TABLE work_orders DROP CONSTRAINT IF EXISTS ex_work_orders_open_customer_window;
+-- btree_gist is left installed, other objects may use it
\ No newline at end of file
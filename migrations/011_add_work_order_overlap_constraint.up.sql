@@ -0,0 +1,29 @@
+-- migrations/011_add_work_order_overlap_constraint.up.sql
+
+-- btree_gist lets the exclusion constraint compare customer_id with = inside a gist index
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- Orders created before this rule can overlap, of each pair the oldest open one is kept and the other is
+-- cancelled like 010 does with duplicates. The orders left overlap no older open one, so no pair remains
+UPDATE work_orders
+SET status = 'cancelled',
+    cancellation_reason = 'orden con horario cruzado, cancelada al no permitir órdenes abiertas que se cruzan',
+    version = version + 1
+WHERE status = 'new'
+  AND id IN (
+    SELECT later.id
+    FROM work_orders AS later
+    JOIN work_orders AS earlier
+      ON earlier.customer_id = later.customer_id
+     AND earlier.status = 'new'
+     AND (earlier.created_at < later.created_at OR (earlier.created_at = later.created_at AND earlier.id < later.id))
+     AND earlier.planned_date_begin < later.planned_date_end
+     AND later.planned_date_begin < earlier.planned_date_end
+    WHERE later.status = 'new'
+  );
+
+-- Open (status new) orders of the same customer can not have overlapping planned windows, [begin, end) so
+-- one order can start when the other ends
+ALTER TABLE work_orders ADD CONSTRAINT ex_work_orders_open_customer_window
+    EXCLUDE USING gist (customer_id WITH =, tstzrange(planned_date_begin, planned_date_end) WITH &&)
+    WHERE (status = 'new');
@@ -0,0 +1,3 @@
+-- migrations/012_create_work_order_status_changes.down.sql
+
+DROP TABLE IF EXISTS work_order_status_changes;
\ No newline at end of file
@@ -0,0 +1,27 @@
+-- migrations/012_create_work_order_status_changes.up.sql
+
+-- Status history of work orders, one row when an order is created, completed or cancelled
+CREATE TABLE IF NOT EXISTS work_order_status_changes (
+    id UUID PRIMARY KEY,
+    work_order_id UUID NOT NULL REFERENCES work_orders(id),
+    actor VARCHAR(255) NOT NULL,
+    old_status work_order_status,
+    new_status work_order_status NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_work_order_status_changes_work_order_id ON work_order_status_changes (work_order_id, created_at);
+
+-- Orders created before get their creation and, when they are no longer new, the move to their current
+-- status so the last entry matches it. When they were completed or cancelled was not kept, the move takes
+-- the creation date plus a microsecond to stay after it. The orders 010 and 011 cancelled have actor 'migration'
+INSERT INTO work_order_status_changes (id, work_order_id, actor, old_status, new_status, created_at)
+SELECT gen_random_uuid(), id, 'system', NULL, 'new', created_at
+FROM work_orders;
+
+INSERT INTO work_order_status_changes (id, work_order_id, actor, old_status, new_status, created_at)
+SELECT gen_random_uuid(), id,
+       CASE WHEN cancellation_reason IN ('orden duplicada, cancelada al limitar a una orden abierta por tipo', 'orden con horario cruzado, cancelada al no permitir órdenes abiertas que se cruzan') THEN 'migration' ELSE 'system' END,
+       'new', status, created_at + INTERVAL '1 microsecond'
+FROM work_orders
+WHERE status <> 'new';
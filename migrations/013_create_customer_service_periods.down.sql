@@ -0,0 +1,3 @@
+-- migrations/013_create_customer_service_periods.down.sql
+
+DROP TABLE IF EXISTS customer_service_periods;
\ No newline at end of file
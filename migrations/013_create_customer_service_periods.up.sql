@@ -0,0 +1,24 @@
+-- migrations/013_create_customer_service_periods.up.sql
+
+-- Intervals in which a customer was active, opened by an activation order and closed by a cancellation order
+CREATE TABLE IF NOT EXISTS customer_service_periods (
+    id UUID PRIMARY KEY,
+    customer_id UUID NOT NULL REFERENCES customers(id),
+    started_at TIMESTAMPTZ NOT NULL,
+    ended_at TIMESTAMPTZ,
+    start_work_order_id UUID REFERENCES work_orders(id),
+    end_work_order_id UUID REFERENCES work_orders(id),
+    CONSTRAINT chk_customer_service_periods_dates CHECK (ended_at IS NULL OR ended_at >= started_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_service_periods_customer_id ON customer_service_periods (customer_id, started_at);
+
+-- At most one open period per customer
+CREATE UNIQUE INDEX IF NOT EXISTS uq_customer_service_periods_open ON customer_service_periods (customer_id) WHERE ended_at IS NULL;
+
+-- Customers before only kept their last period in start_date and end_date, the older ones are lost
+INSERT INTO customer_service_periods (id, customer_id, started_at, ended_at, start_work_order_id, end_work_order_id)
+SELECT gen_random_uuid(), id, start_date, CASE WHEN is_active THEN NULL ELSE end_date END, NULL, NULL
+FROM customers
+WHERE start_date IS NOT NULL
+  AND (is_active OR end_date IS NOT NULL);
@@ -0,0 +1,4 @@
+-- migrations/014_add_idempotency_key_headers_and_lock.down.sql
+
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS headers;
\ No newline at end of file
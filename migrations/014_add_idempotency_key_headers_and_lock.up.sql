@@ -0,0 +1,6 @@
+-- migrations/014_add_idempotency_key_headers_and_lock.up.sql
+
+-- Headers replayed with the stored response (ETag, Location) and how long the first request holds the key.
+-- Keys reserved before this migration get an expired lock, so the ones that never finished can be used again
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS headers JSONB;
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ NOT NULL DEFAULT 'epoch';
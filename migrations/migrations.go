@@ -0,0 +1,28 @@
+// migrations/migrations.go
+
+// Package migrations embeds the sql files in the binary, storage.Migrator applies them
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// Files are the migrations of postgres
+//
+//go:embed *.sql
+var Files embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFiles embed.FS
+
+// SQLite has the same versions as Files written for sqlite, used for local development and tests
+var SQLite, _ = fs.Sub(sqliteFiles, "sqlite")
+
+// For returns the migrations of the storage driver, postgres or sqlite
+func For(driver string) fs.FS {
+	if driver == "sqlite" {
+		return SQLite
+	}
+	return Files
+}
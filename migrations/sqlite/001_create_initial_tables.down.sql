@@ -0,0 +1,4 @@
+-- migrations/sqlite/001_create_initial_tables.down.sql
+
+DROP TABLE IF EXISTS work_orders;
+DROP TABLE IF EXISTS customers;
\ No newline at end of file
@@ -0,0 +1,28 @@
+-- migrations/sqlite/001_create_initial_tables.up.sql
+
+-- Same tables as migrations/001, SQLite has no uuid nor enums so ids are TEXT and the status is checked
+-- with a CHECK. Dates are DATETIME so the driver reads them as time.Time
+
+-- Customers table
+CREATE TABLE IF NOT EXISTS customers (
+    id TEXT PRIMARY KEY NOT NULL,
+    first_name VARCHAR(255) NOT NULL,
+    last_name VARCHAR(255) NOT NULL,
+    address TEXT NOT NULL,
+    start_date DATETIME,
+    end_date DATETIME,
+    is_active BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Work_orders table
+CREATE TABLE IF NOT EXISTS work_orders (
+    id TEXT PRIMARY KEY NOT NULL,
+    customer_id TEXT NOT NULL REFERENCES customers(id),
+    description TEXT NOT NULL,
+    planned_date_begin DATETIME NOT NULL,
+    planned_date_end DATETIME NOT NULL,
+    status TEXT NOT NULL DEFAULT 'new' CHECK (status IN ('new', 'done', 'cancelled')),
+    type VARCHAR(50) NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
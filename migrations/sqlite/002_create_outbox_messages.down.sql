@@ -0,0 +1,3 @@
+-- migrations/sqlite/002_create_outbox_messages.down.sql
+
+DROP TABLE IF EXISTS outbox_messages;
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- migrations/sqlite/002_create_outbox_messages.up.sql
+
+-- Outbox table, events are written in the same transaction as the change
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id TEXT PRIMARY KEY NOT NULL,
+    event VARCHAR(100) NOT NULL,
+    payload TEXT NOT NULL CHECK (json_valid(payload)),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    published_at DATETIME
+);
+
+-- The relay only reads pending messages
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_pending ON outbox_messages (created_at) WHERE published_at IS NULL;
@@ -0,0 +1,3 @@
+-- migrations/sqlite/003_add_work_order_cancellation_reason.down.sql
+
+ALTER TABLE work_orders DROP COLUMN cancellation_reason;
\ No newline at end of file
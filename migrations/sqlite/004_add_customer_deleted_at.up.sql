@@ -0,0 +1,6 @@
+-- migrations/sqlite/004_add_customer_deleted_at.up.sql
+
+-- Soft delete for customers, deleted ones are hidden by gorm
+ALTER TABLE customers ADD COLUMN deleted_at DATETIME;
+
+CREATE INDEX IF NOT EXISTS idx_customers_deleted_at ON customers (deleted_at);
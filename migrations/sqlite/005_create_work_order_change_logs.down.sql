@@ -0,0 +1,3 @@
+-- migrations/sqlite/005_create_work_order_change_logs.down.sql
+
+DROP TABLE IF EXISTS work_order_change_logs;
\ No newline at end of file
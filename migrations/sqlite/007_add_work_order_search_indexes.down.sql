@@ -0,0 +1,3 @@
+-- migrations/sqlite/007_add_work_order_search_indexes.down.sql
+
+DROP INDEX IF EXISTS idx_work_orders_type_status;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- migrations/sqlite/007_add_work_order_search_indexes.up.sql
+
+-- There is no trigram index in SQLite, description LIKE '%text%' scans the table
+
+-- Filters by type and status
+CREATE INDEX IF NOT EXISTS idx_work_orders_type_status ON work_orders (type, status);
@@ -0,0 +1,3 @@
+-- migrations/sqlite/008_create_idempotency_keys.down.sql
+
+DROP TABLE IF EXISTS idempotency_keys;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- migrations/sqlite/009_add_version_columns.down.sql
+
+ALTER TABLE work_orders DROP COLUMN version;
+ALTER TABLE customers DROP COLUMN version;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- migrations/sqlite/011_add_work_order_overlap_constraint.down.sql
+
+DROP TRIGGER IF EXISTS trg_work_orders_open_customer_window_update;
+DROP TRIGGER IF EXISTS trg_work_orders_open_customer_window_insert;
\ No newline at end of file
@@ -0,0 +1,50 @@
+-- migrations/sqlite/011_add_work_order_overlap_constraint.up.sql
+
+-- Same as migrations/011, orders created before this rule can overlap, of each pair the oldest open one
+-- is kept and the other is cancelled like 010 does with duplicates. The orders left overlap no older open
+-- one, so no pair remains
+UPDATE work_orders
+SET status = 'cancelled',
+    cancellation_reason = 'orden con horario cruzado, cancelada al no permitir órdenes abiertas que se cruzan',
+    version = version + 1
+WHERE status = 'new'
+  AND id IN (
+    SELECT later.id
+    FROM work_orders AS later
+    JOIN work_orders AS earlier
+      ON earlier.customer_id = later.customer_id
+     AND earlier.status = 'new'
+     AND (earlier.created_at < later.created_at OR (earlier.created_at = later.created_at AND earlier.id < later.id))
+     AND earlier.planned_date_begin < later.planned_date_end
+     AND later.planned_date_begin < earlier.planned_date_end
+    WHERE later.status = 'new'
+  );
+
+-- SQLite has no exclusion constraints, these triggers reject an open (status new) order whose [begin, end)
+-- window overlaps another open order of the same customer
+CREATE TRIGGER IF NOT EXISTS trg_work_orders_open_customer_window_insert
+BEFORE INSERT ON work_orders
+WHEN NEW.status = 'new' AND EXISTS (
+    SELECT 1 FROM work_orders
+    WHERE customer_id = NEW.customer_id
+      AND status = 'new'
+      AND planned_date_begin < NEW.planned_date_end
+      AND NEW.planned_date_begin < planned_date_end
+)
+BEGIN
+    SELECT RAISE(ABORT, 'work_order_overlap');
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_work_orders_open_customer_window_update
+BEFORE UPDATE ON work_orders
+WHEN NEW.status = 'new' AND EXISTS (
+    SELECT 1 FROM work_orders
+    WHERE customer_id = NEW.customer_id
+      AND id <> NEW.id
+      AND status = 'new'
+      AND planned_date_begin < NEW.planned_date_end
+      AND NEW.planned_date_begin < planned_date_end
+)
+BEGIN
+    SELECT RAISE(ABORT, 'work_order_overlap');
+END;
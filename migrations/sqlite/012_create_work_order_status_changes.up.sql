@@ -0,0 +1,35 @@
+-- migrations/sqlite/012_create_work_order_status_changes.up.sql
+
+-- Status history of work orders, one row when an order is created, completed or cancelled
+CREATE TABLE IF NOT EXISTS work_order_status_changes (
+    id TEXT PRIMARY KEY NOT NULL,
+    work_order_id TEXT NOT NULL REFERENCES work_orders(id),
+    actor VARCHAR(255) NOT NULL,
+    old_status TEXT CHECK (old_status IN ('new', 'done', 'cancelled')),
+    new_status TEXT NOT NULL CHECK (new_status IN ('new', 'done', 'cancelled')),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_work_order_status_changes_work_order_id ON work_order_status_changes (work_order_id, created_at);
+
+-- Same backfill as migrations/012, the creation and the move to the current status of orders no longer new.
+-- SQLite has no gen_random_uuid, the id is a random version 4 uuid built from randomblob
+INSERT INTO work_order_status_changes (id, work_order_id, actor, old_status, new_status, created_at)
+SELECT lower(
+           hex(randomblob(4)) || '-' || hex(randomblob(2)) || '-4' || substr(hex(randomblob(2)), 2) || '-' ||
+           substr('89ab', 1 + (abs(random()) % 4), 1) || substr(hex(randomblob(2)), 2) || '-' || hex(randomblob(6))
+       ),
+       id, 'system', NULL, 'new', created_at
+FROM work_orders;
+
+-- a millisecond after the creation, in the format the driver writes
+INSERT INTO work_order_status_changes (id, work_order_id, actor, old_status, new_status, created_at)
+SELECT lower(
+           hex(randomblob(4)) || '-' || hex(randomblob(2)) || '-4' || substr(hex(randomblob(2)), 2) || '-' ||
+           substr('89ab', 1 + (abs(random()) % 4), 1) || substr(hex(randomblob(2)), 2) || '-' || hex(randomblob(6))
+       ),
+       id,
+       CASE WHEN cancellation_reason IN ('orden duplicada, cancelada al limitar a una orden abierta por tipo', 'orden con horario cruzado, cancelada al no permitir órdenes abiertas que se cruzan') THEN 'migration' ELSE 'system' END,
+       'new', status, strftime('%Y-%m-%d %H:%M:%f', created_at, '+0.001 seconds') || '+00:00'
+FROM work_orders
+WHERE status <> 'new';
@@ -0,0 +1,29 @@
+-- migrations/sqlite/013_create_customer_service_periods.up.sql
+
+-- Intervals in which a customer was active, opened by an activation order and closed by a cancellation order
+CREATE TABLE IF NOT EXISTS customer_service_periods (
+    id TEXT PRIMARY KEY NOT NULL,
+    customer_id TEXT NOT NULL REFERENCES customers(id),
+    started_at DATETIME NOT NULL,
+    ended_at DATETIME,
+    start_work_order_id TEXT REFERENCES work_orders(id),
+    end_work_order_id TEXT REFERENCES work_orders(id),
+    CONSTRAINT chk_customer_service_periods_dates CHECK (ended_at IS NULL OR ended_at >= started_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_service_periods_customer_id ON customer_service_periods (customer_id, started_at);
+
+-- At most one open period per customer
+CREATE UNIQUE INDEX IF NOT EXISTS uq_customer_service_periods_open ON customer_service_periods (customer_id) WHERE ended_at IS NULL;
+
+-- Customers before only kept their last period in start_date and end_date, the older ones are lost.
+-- SQLite has no gen_random_uuid, the id is a random version 4 uuid built from randomblob
+INSERT INTO customer_service_periods (id, customer_id, started_at, ended_at, start_work_order_id, end_work_order_id)
+SELECT lower(
+           hex(randomblob(4)) || '-' || hex(randomblob(2)) || '-4' || substr(hex(randomblob(2)), 2) || '-' ||
+           substr('89ab', 1 + (abs(random()) % 4), 1) || substr(hex(randomblob(2)), 2) || '-' || hex(randomblob(6))
+       ),
+       id, start_date, CASE WHEN is_active THEN NULL ELSE end_date END, NULL, NULL
+FROM customers
+WHERE start_date IS NOT NULL
+  AND (is_active OR end_date IS NOT NULL);
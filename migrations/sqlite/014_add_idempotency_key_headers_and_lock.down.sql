@@ -0,0 +1,4 @@
+-- migrations/sqlite/014_add_idempotency_key_headers_and_lock.down.sql
+
+ALTER TABLE idempotency_keys DROP COLUMN locked_until;
+ALTER TABLE idempotency_keys DROP COLUMN headers;
\ No newline at end of file
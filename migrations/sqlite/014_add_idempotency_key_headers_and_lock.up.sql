@@ -0,0 +1,6 @@
+-- migrations/sqlite/014_add_idempotency_key_headers_and_lock.up.sql
+
+-- Headers replayed with the stored response (ETag, Location) and how long the first request holds the key.
+-- Keys reserved before this migration get an expired lock, so the ones that never finished can be used again
+ALTER TABLE idempotency_keys ADD COLUMN headers TEXT;
+ALTER TABLE idempotency_keys ADD COLUMN locked_until DATETIME NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';